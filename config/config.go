@@ -2,34 +2,65 @@ package config
 
 import (
 	"os"
+	"strings"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	db "bombparty.com/bombparty-api/database"
 	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/detonation"
+	"bombparty.com/bombparty-api/pkg/eventlog"
+	"bombparty.com/bombparty-api/pkg/recurrence"
+	"bombparty.com/bombparty-api/pkg/result"
 )
 
 type Config struct {
 	Port                string
 	JwtKey              string
+	AdminEmails         []string
 	UserRepository      dbmodel.UserRepository
 	InventoryRepository dbmodel.InventoryRepository
 	GameRepository      dbmodel.GameRepository
 	TeamRepository      dbmodel.TeamRepository
 	BombRepository      dbmodel.BombRepository
+	BombTypeRepository  dbmodel.BombTypeRepository
+
+	DetonationRepository dbmodel.DetonationRepository
+	DefusalRepository    dbmodel.DefusalRepository
+	TerritoryRepository  dbmodel.TerritoryRepository
+	PositionRepository   dbmodel.PositionRepository
+	TemplateRepository   dbmodel.GameTemplateRepository
+	RecurrenceRepository dbmodel.RecurrenceRepository
+	EventRepository      dbmodel.GameEventRepository
+	ScoreRepository      dbmodel.ScoreRepository
+	ResultRepository     dbmodel.GameResultRepository
+	Events               *eventlog.Log
+	Detonator            *detonation.Scheduler
+	Materialiser         *recurrence.Materialiser
+	Finisher             *result.Finisher
 }
 
 func New() (*Config, error) {
+	databaseSession, err := gorm.Open(sqlite.Open("bomb-party.db"), &gorm.Config{})
+	if err != nil {
+		return &Config{}, err
+	}
+	return NewWithDatabase(databaseSession)
+}
+
+// Configuration from the environment on top of the database session, migrated first
+func NewWithDatabase(databaseSession *gorm.DB) (*Config, error) {
 	config := Config{
 		JwtKey: os.Getenv("JWT_SECRET_KEY"),
 		Port:   os.Getenv("PORT"),
 	}
-
-	databaseSession, err := gorm.Open(sqlite.Open("bomb-party.db"), &gorm.Config{})
-	if err != nil {
-		return &config, err
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			config.AdminEmails = append(config.AdminEmails, email)
+		}
 	}
+
 	db.Migrate(databaseSession)
 
 	config.UserRepository = dbmodel.NewUserRepository(databaseSession)
@@ -37,5 +68,26 @@ func New() (*Config, error) {
 	config.GameRepository = dbmodel.NewGameRepository(databaseSession)
 	config.TeamRepository = dbmodel.NewTeamRepository(databaseSession)
 	config.BombRepository = dbmodel.NewBombRepository(databaseSession)
+	config.BombTypeRepository = dbmodel.NewBombTypeRepository(databaseSession)
+	config.DetonationRepository = dbmodel.NewDetonationRepository(databaseSession)
+	config.DefusalRepository = dbmodel.NewDefusalRepository(databaseSession)
+	config.TerritoryRepository = dbmodel.NewTerritoryRepository(databaseSession)
+	config.PositionRepository = dbmodel.NewPositionRepository(databaseSession)
+	config.TemplateRepository = dbmodel.NewGameTemplateRepository(databaseSession)
+	config.RecurrenceRepository = dbmodel.NewRecurrenceRepository(databaseSession)
+	config.EventRepository = dbmodel.NewGameEventRepository(databaseSession)
+	config.ScoreRepository = dbmodel.NewScoreRepository(databaseSession)
+	config.ResultRepository = dbmodel.NewGameResultRepository(databaseSession)
+
+	if err := config.UserRepository.PromoteAdmins(config.AdminEmails); err != nil {
+		return &config, err
+	}
+
+	config.Events = eventlog.New(config.EventRepository)
+	config.Detonator = detonation.New(config.BombRepository, config.BombTypeRepository, config.GameRepository,
+		config.DetonationRepository, config.TerritoryRepository,
+		config.UserRepository, config.PositionRepository, config.Events)
+	config.Materialiser = recurrence.New(config.RecurrenceRepository, config.TemplateRepository)
+	config.Finisher = result.New(config.GameRepository, config.ResultRepository, config.Events)
 	return &config, nil
 }
@@ -2,11 +2,14 @@ package database
 
 import (
 	"log"
+	"slices"
 
 	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
@@ -34,7 +37,211 @@ func Migrate(db *gorm.DB) {
 		&dbmodel.BombEntry{},
 		&dbmodel.UserEntry{},
 		&dbmodel.InventoryEntry{},
+		&dbmodel.DetonationEntry{},
+		&dbmodel.TerritoryCellEntry{},
+		&dbmodel.PositionEntry{},
+		&dbmodel.GameTemplateEntry{},
+		&dbmodel.RecurrenceEntry{},
+		&dbmodel.OccurrenceEntry{},
+		&dbmodel.GameEventEntry{},
+		&dbmodel.ScoreEventEntry{},
+		&dbmodel.GameResultEntry{},
+		&dbmodel.BombTypeEntry{},
+		&dbmodel.DefusalEntry{},
 	)
 
+	migrateBombGames(db)
+	migrateBombGeohashes(db)
+	migrateGameGeohashes(db)
+	migrateJoinCodes(db)
+	migrateTeamScores(db)
+	migrateGameResults(db)
+	migrateDetonationChains(db)
+	seedBombTypes(db)
+	migrateBombBehaviours(db)
+
 	log.Println("Database migrated successfully")
 }
+
+// Bombs used to have no game, attach them to the game of the team of their owner
+func migrateBombGames(db *gorm.DB) {
+
+	result := db.Exec(`UPDATE bomb_entries SET
+		id_team = (SELECT user_entries.id_team FROM user_entries WHERE user_entries.id_user = bomb_entries.id_user),
+		id_game = (SELECT team_entries.id_game FROM user_entries
+			JOIN team_entries ON team_entries.id_team = user_entries.id_team
+			WHERE user_entries.id_user = bomb_entries.id_user)
+		WHERE id_game IS NULL`)
+	if result.Error != nil {
+		log.Println("Failed to attach bombs to their game:", result.Error)
+		return
+	}
+
+	var orphans int64
+	db.Model(&dbmodel.BombEntry{}).Where("id_game IS NULL").Count(&orphans)
+	if result.RowsAffected > 0 || orphans > 0 {
+		log.Printf("Attached %d bombs to their game, %d bombs without game are left unlisted\n", result.RowsAffected-orphans, orphans)
+	}
+}
+
+// Index the bombs placed before the geohash column existed
+func migrateBombGeohashes(db *gorm.DB) {
+
+	var bombs []*dbmodel.BombEntry
+	count := 0
+	result := db.Where("geohash IS NULL OR geohash = ''").FindInBatches(&bombs, 1000, func(tx *gorm.DB, batch int) error {
+		for _, bomb := range bombs {
+			hash := geo.Geohash(bomb.Point(), geo.GeohashPrecision)
+			if err := db.Model(bomb).UpdateColumn("geohash", hash).Error; err != nil {
+				return err
+			}
+		}
+		count += len(bombs)
+		return nil
+	})
+	if result.Error != nil {
+		log.Println("Failed to index bombs:", result.Error)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("Indexed %d bombs\n", count)
+	}
+}
+
+// Index the games created before the geohash column existed
+func migrateGameGeohashes(db *gorm.DB) {
+
+	var games []*dbmodel.GameEntry
+	if err := db.Select("id_game", "center_latitude", "center_longitude").
+		Where("geohash IS NULL OR geohash = ''").Find(&games).Error; err != nil {
+		log.Println("Failed to fetch games without geohash:", err)
+		return
+	}
+
+	for _, game := range games {
+		hash := geo.Geohash(game.Circle().Center, geo.GeohashPrecision)
+		if err := db.Model(game).UpdateColumn("geohash", hash).Error; err != nil {
+			log.Println("Failed to index game", game.IDGame, err)
+		}
+	}
+	if len(games) > 0 {
+		log.Printf("Indexed %d games\n", len(games))
+	}
+}
+
+// Give a join code to the games created before they had one
+func migrateJoinCodes(db *gorm.DB) {
+
+	var games []*dbmodel.GameEntry
+	if err := db.Select("id_game").Where("join_code IS NULL OR join_code = ''").Find(&games).Error; err != nil {
+		log.Println("Failed to fetch games without join code:", err)
+		return
+	}
+
+	for _, game := range games {
+		if err := db.Model(game).UpdateColumn("join_code", dbmodel.NewJoinCode()).Error; err != nil {
+			log.Println("Failed to give a join code to game", game.IDGame, err)
+		}
+	}
+}
+
+// Scores used to be plain numbers, record them as legacy score events so recomputing them keeps them
+func migrateTeamScores(db *gorm.DB) {
+
+	var teams []*dbmodel.TeamEntry
+	if err := db.Where("score <> 0 AND NOT EXISTS (SELECT 1 FROM score_event_entries WHERE score_event_entries.id_team = team_entries.id_team)").
+		Find(&teams).Error; err != nil {
+		log.Println("Failed to fetch teams without score events:", err)
+		return
+	}
+
+	for _, team := range teams {
+		// The score of the team already holds the points, only the event is missing
+		if err := db.Create(&dbmodel.ScoreEventEntry{
+			IDGame: team.IDGame,
+			IDTeam: team.IDTeam,
+			Reason: dbmodel.ScoreReasonLegacy,
+			Points: team.Score,
+			At:     team.UpdatedAt,
+		}).Error; err != nil {
+			log.Println("Failed to record the score of team", team.IDTeam, err)
+		}
+	}
+	if len(teams) > 0 {
+		log.Printf("Recorded the score of %d teams as legacy score events\n", len(teams))
+	}
+}
+
+// Games finished before results existed get theirs from what is left of them
+func migrateGameResults(db *gorm.DB) {
+
+	var games []*dbmodel.GameEntry
+	if err := db.Where("status = ? AND NOT EXISTS (SELECT 1 FROM game_result_entries WHERE game_result_entries.id_game = game_entries.id_game)",
+		dbmodel.GameStatusFinished).Find(&games).Error; err != nil {
+		log.Println("Failed to fetch finished games without results:", err)
+		return
+	}
+
+	results := dbmodel.NewGameResultRepository(db)
+	for _, game := range games {
+		// The last update of a finished game is its end
+		if _, err := results.Snapshot(game.IDGame, game.UpdatedAt); err != nil {
+			log.Println("Failed to freeze the results of game", game.IDGame, err)
+		}
+	}
+	if len(games) > 0 {
+		log.Printf("Froze the results of %d finished games\n", len(games))
+	}
+}
+
+// Detonations recorded before chain reactions each started their own chain
+func migrateDetonationChains(db *gorm.DB) {
+
+	if err := db.Exec("UPDATE detonation_entries SET id_chain = id_bomb WHERE id_chain = 0").Error; err != nil {
+		log.Println("Failed to start the chains of past detonations:", err)
+	}
+}
+
+// Fill the bomb type registry with the default types, and the free-form types used before it existed
+func seedBombTypes(db *gorm.DB) {
+
+	types := slices.Clone(dbmodel.DefaultBombTypes)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&types).Error; err != nil {
+		log.Println("Failed to register the default bomb types:", err)
+		return
+	}
+
+	var unknown []string
+	if err := db.Raw(`SELECT type_bomb FROM bomb_entries WHERE type_bomb <> ''
+		UNION SELECT type_bomb FROM inventory_entries WHERE type_bomb <> ''
+		EXCEPT SELECT type_bomb FROM bomb_type_entries`).Scan(&unknown).Error; err != nil {
+		log.Println("Failed to fetch unregistered bomb types:", err)
+		return
+	}
+
+	// They used to explode like classic bombs, keep them disabled so they are not handed out
+	classic := dbmodel.DefaultBombTypes[0]
+	for _, typeBomb := range unknown {
+		entry := classic
+		entry.TypeBomb = typeBomb
+		entry.Name = typeBomb
+		entry.Enabled = false
+		if err := db.Create(&entry).Error; err != nil {
+			log.Println("Failed to register bomb type", typeBomb, err)
+		}
+	}
+	if len(unknown) > 0 {
+		log.Printf("Registered %d unknown bomb types as disabled\n", len(unknown))
+	}
+}
+
+// Every bomb and bomb type was timed before mines existed
+func migrateBombBehaviours(db *gorm.DB) {
+
+	for _, table := range []string{"bomb_type_entries", "bomb_entries"} {
+		if err := db.Exec("UPDATE "+table+" SET behaviour = ? WHERE behaviour IS NULL OR behaviour = ''", dbmodel.BombBehaviourTimed).Error; err != nil {
+			log.Println("Failed to set the behaviour of", table, err)
+		}
+	}
+}
@@ -1,24 +1,96 @@
 package dbmodel
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+type BombStatus string
+
+const (
+	BombStatusArmed     BombStatus = "armed"
+	BombStatusDetonated BombStatus = "detonated"
+	BombStatusExpired   BombStatus = "expired" // Still armed when its game finished, it will never explode
+	BombStatusDefused   BombStatus = "defused" // Made safe by an opponent before it exploded
+)
+
+var ErrNoBombLeft = errors.New("no bomb of this type left in the inventory")
+
+type ThrottleReason string
+
+const (
+	ThrottleCooldown     ThrottleReason = "cooldown"         // Last bomb of the player placed too recently
+	ThrottleTypeCooldown ThrottleReason = "type_cooldown"    // Last bomb of the same type placed too recently
+	ThrottleActiveBombs  ThrottleReason = "max_active_bombs" // Too many bombs of the player still armed
 )
 
+// The player cannot place the bomb before RetryAt, the latest of the limits they hit
+type ThrottleError struct {
+	Reason  ThrottleReason
+	RetryAt time.Time
+}
+
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("%s, next bomb can be placed at %s", e.Reason, e.RetryAt.Format(time.RFC3339))
+}
+
 type BombEntry struct {
-	BombID   int       `gorm:"type:int; primaryKey"`
-	Lat      float32   `json:"lat"`
-	Long     float32   `json:"long"`
-	TypeBomb string    `json:"type_bomb"`
-	IdUser   uuid.UUID `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"care_taker"`
+	BombID     int        `gorm:"type:int; primaryKey"`
+	Lat        float32    `json:"lat"`
+	Long       float32    `json:"long"`
+	Geohash    string     `gorm:"type:varchar(12);index:idx_bomb_geohash,priority:2" json:"-"`
+	TypeBomb   string     `json:"type_bomb"`
+	IdUser     uuid.UUID  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"care_taker"`
+	IDGame     uuid.UUID  `gorm:"type:uuid;index;index:idx_bomb_geohash,priority:1" json:"id_game"`
+	Game       *GameEntry `gorm:"foreignKey:IDGame;references:IDGame;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	IDTeam     uuid.UUID  `gorm:"type:uuid;index" json:"id_team"` // Team of the user when the bomb was placed
+	PlacedAt   time.Time  `json:"placed_at"`
+	Fuse       int        `json:"fuse"` // Seconds between placement and detonation, or expiry for mines
+	DetonateAt time.Time  `json:"detonate_at"`
+	Status     BombStatus `gorm:"type:varchar(16);index" json:"status"`
+	DefusedBy  *uuid.UUID `gorm:"type:uuid" json:"defused_by"`
+	DefusedAt  *time.Time `json:"defused_at"`
+
+	// Taken from the type when the bomb is placed
+	Behaviour     BombBehaviour `gorm:"type:varchar(16)" json:"behaviour"`
+	TriggerRadius float64       `json:"trigger_radius"`
+}
+
+// Keep the geohash in sync with the coordinates of the bomb
+func (b *BombEntry) BeforeSave(tx *gorm.DB) error {
+	b.Geohash = geo.Geohash(b.Point(), geo.GeohashPrecision)
+	return nil
+}
+
+func (b *BombEntry) Point() geo.Point {
+	return geo.NewPoint(b.Lat, b.Long)
+}
+
+func (b *BombEntry) IsMine() bool {
+	return b.Behaviour == BombBehaviourMine
 }
 
 type BombRepository interface {
-	Create(bomb *BombEntry) (*BombEntry, error)
+	Create(bomb *BombEntry, ruleset *rules.Ruleset) (*BombEntry, error)
 	FindAll() ([]*BombEntry, error)
-	FindAllByUserId(userId int) ([]*BombEntry, error)
+	FindArmed() ([]*BombEntry, error)
+	FindAllByUserId(idUser uuid.UUID) ([]*BombEntry, error)
+	FindAllByGameId(idGame uuid.UUID) ([]*BombEntry, error)
+	FindNearby(idGame uuid.UUID, circle geo.Circle) ([]*BombEntry, error)
+	FindMinesTriggered(idGame uuid.UUID, point geo.Point) ([]*BombEntry, error)
 	FindById(id int) (*BombEntry, error)
 	Update(bomb *BombEntry) (*BombEntry, error)
+	Expire(id int) error
 	Delete(id int) error
 }
 
@@ -30,13 +102,93 @@ func NewBombRepository(db *gorm.DB) BombRepository {
 	return &bombRepository{db: db}
 }
 
-func (r *bombRepository) Create(bomb *BombEntry) (*BombEntry, error) {
-	if err := r.db.Create(bomb).Error; err != nil {
+// Place the bomb if the player is within the limits of the ruleset, taking it from their inventory.
+// Everything happens in one transaction so a bomb is never placed without being paid for
+func (r *bombRepository) Create(bomb *BombEntry, ruleset *rules.Ruleset) (*BombEntry, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		throttle, err := placementThrottle(tx, bomb, ruleset)
+		if err != nil {
+			return err
+		}
+		if throttle != nil {
+			return throttle
+		}
+
+		// Only decremented while some are left, concurrent placements can't go below zero
+		consume := tx.Model(&InventoryEntry{}).
+			Where("id_user = ? AND type_bomb = ? AND amount > 0", bomb.IdUser, bomb.TypeBomb).
+			UpdateColumn("amount", gorm.Expr("amount - 1"))
+		if consume.Error != nil {
+			return consume.Error
+		}
+		if consume.RowsAffected == 0 {
+			return ErrNoBombLeft
+		}
+
+		return tx.Omit("Game").Create(bomb).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return bomb, nil
 }
 
+// Latest of the limits of the ruleset holding back the bomb, nil when it can be placed
+func placementThrottle(tx *gorm.DB, bomb *BombEntry, ruleset *rules.Ruleset) (*ThrottleError, error) {
+	var throttle *ThrottleError
+	hold := func(reason ThrottleReason, until time.Time) {
+		if until.After(bomb.PlacedAt) && (throttle == nil || until.After(throttle.RetryAt)) {
+			throttle = &ThrottleError{Reason: reason, RetryAt: until}
+		}
+	}
+	player := tx.Where("id_game = ? AND id_user = ?", bomb.IDGame, bomb.IdUser)
+
+	if cooldown := ruleset.Cooldown(); cooldown > 0 {
+		var last BombEntry
+		err := player.Session(&gorm.Session{}).Order("placed_at DESC").First(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			hold(ThrottleCooldown, last.PlacedAt.Add(cooldown))
+		}
+	}
+
+	if cooldown := ruleset.TypeCooldown(bomb.TypeBomb); cooldown > 0 {
+		var lastOfType BombEntry
+		err := player.Session(&gorm.Session{}).Where("type_bomb = ?", bomb.TypeBomb).
+			Order("placed_at DESC").
+			First(&lastOfType).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			hold(ThrottleTypeCooldown, lastOfType.PlacedAt.Add(cooldown))
+		}
+	}
+
+	// A slot frees up each time one of the armed bombs explodes
+	if ruleset.MaxActiveBombs > 0 {
+		var armed []*BombEntry
+		if err := player.Session(&gorm.Session{}).Where("status = ?", BombStatusArmed).
+			Order("detonate_at").
+			Find(&armed).Error; err != nil {
+			return nil, err
+		}
+		if len(armed) >= ruleset.MaxActiveBombs {
+			until := armed[len(armed)-ruleset.MaxActiveBombs].DetonateAt
+			// Overdue bombs explode as soon as the scheduler gets to them, the player still waits
+			// for that to happen and retries a second later rather than right away
+			if !until.After(bomb.PlacedAt) {
+				until = bomb.PlacedAt.Add(rules.MinCooldown)
+			}
+			hold(ThrottleActiveBombs, until)
+		}
+	}
+
+	return throttle, nil
+}
+
 func (r *bombRepository) FindAll() ([]*BombEntry, error) {
 	var bombs []*BombEntry
 	if err := r.db.Find(&bombs).Error; err != nil {
@@ -45,33 +197,152 @@ func (r *bombRepository) FindAll() ([]*BombEntry, error) {
 	return bombs, nil
 }
 
-func (r *bombRepository) FindAllByUserId(userId int) ([]*BombEntry, error) {
+func (r *bombRepository) FindArmed() ([]*BombEntry, error) {
+	var bombs []*BombEntry
+	if err := r.db.Where("status = ?", BombStatusArmed).Order("detonate_at").Find(&bombs).Error; err != nil {
+		return nil, err
+	}
+	return bombs, nil
+}
+
+func (r *bombRepository) FindAllByUserId(idUser uuid.UUID) ([]*BombEntry, error) {
+	var bombs []*BombEntry
+	if err := r.db.Where("id_user = ?", idUser).Find(&bombs).Error; err != nil {
+		return nil, err
+	}
+
+	return bombs, nil
+}
+
+func (r *bombRepository) FindAllByGameId(idGame uuid.UUID) ([]*BombEntry, error) {
 	var bombs []*BombEntry
-	if err := r.db.Where("type_bomb = ?", userId).Find(&bombs).Error; err != nil {
+	if err := r.db.Where("id_game = ?", idGame).Find(&bombs).Error; err != nil {
 		return nil, err
 	}
+	return bombs, nil
+}
+
+// Bombs of the game inside the circle, closest first.
+// Candidates are fetched from the geohash index then filtered on their exact distance.
+func (r *bombRepository) FindNearby(idGame uuid.UUID, circle geo.Circle) ([]*BombEntry, error) {
+	cover, args := geohashCover(circle)
 
+	var candidates []*BombEntry
+	if err := r.db.Where("id_game = ?", idGame).Where(cover, args...).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	distances := map[int]float64{}
+	bombs := []*BombEntry{}
+	for _, bomb := range candidates {
+		distance := geo.Distance(circle.Center, bomb.Point())
+		if distance <= circle.Radius {
+			distances[bomb.BombID] = distance
+			bombs = append(bombs, bomb)
+		}
+	}
+	slices.SortFunc(bombs, func(a, b *BombEntry) int {
+		return cmp.Or(cmp.Compare(distances[a.BombID], distances[b.BombID]), a.BombID-b.BombID)
+	})
 	return bombs, nil
 }
 
+// Armed mines of the game whose trigger radius reaches the point, closest first.
+// Candidates are fetched from the geohash index within the widest trigger radius
+func (r *bombRepository) FindMinesTriggered(idGame uuid.UUID, point geo.Point) ([]*BombEntry, error) {
+	cover, args := geohashCover(geo.Circle{Center: point, Radius: MaxTriggerRadius})
+
+	var candidates []*BombEntry
+	if err := r.db.Where("id_game = ? AND status = ? AND behaviour = ?", idGame, BombStatusArmed, BombBehaviourMine).
+		Where(cover, args...).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	distances := map[int]float64{}
+	mines := []*BombEntry{}
+	for _, mine := range candidates {
+		distance := geo.Distance(point, mine.Point())
+		if distance <= mine.TriggerRadius {
+			distances[mine.BombID] = distance
+			mines = append(mines, mine)
+		}
+	}
+	slices.SortFunc(mines, func(a, b *BombEntry) int {
+		return cmp.Or(cmp.Compare(distances[a.BombID], distances[b.BombID]), a.BombID-b.BombID)
+	})
+	return mines, nil
+}
+
+// Condition on the geohash column matching the cells covering the circle
+func geohashCover(circle geo.Circle) (string, []interface{}) {
+	prefixes := geo.GeohashCover(circle)
+
+	// Prefix matches as ranges so the index is used whatever the collation
+	ranges := make([]string, len(prefixes))
+	args := []interface{}{}
+	for i, prefix := range prefixes {
+		ranges[i] = "(geohash >= ? AND geohash < ?)"
+		args = append(args, prefix, prefix+"~")
+	}
+	return "(" + strings.Join(ranges, " OR ") + ")", args
+}
+
 func (r *bombRepository) FindById(id int) (*BombEntry, error) {
 	var bomb BombEntry
-	if err := r.db.Find(&bomb, id).Error; err != nil {
+	if err := r.db.First(&bomb, id).Error; err != nil {
 		return nil, err
 	}
 	return &bomb, nil
 }
 
 func (r *bombRepository) Update(bomb *BombEntry) (*BombEntry, error) {
-	if err := r.db.Save(bomb).Error; err != nil {
+	if err := r.db.Omit("Game").Save(bomb).Error; err != nil {
 		return nil, err
 	}
 	return bomb, nil
 }
 
-func (r *bombRepository) Delete(id int) error {
-	if err := r.db.Delete(&BombEntry{}, id).Error; err != nil {
-		return err
+// Disarm a bomb whose time is over without exploding it
+func (r *bombRepository) Expire(id int) error {
+	result := r.db.Model(&BombEntry{}).
+		Where("bomb_id = ? AND status = ?", id, BombStatusArmed).
+		Update("status", BombStatusExpired)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBombNotArmed
 	}
 	return nil
 }
+
+// Remove the bomb, giving it back to its owner if it was still armed
+func (r *bombRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bomb BombEntry
+		if err := tx.First(&bomb, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id_bomb = ?", id).Delete(&DefusalEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&BombEntry{}, id).Error; err != nil {
+			return err
+		}
+		if bomb.Status != BombStatusArmed {
+			return nil
+		}
+
+		refund := tx.Model(&InventoryEntry{}).
+			Where("id_user = ? AND type_bomb = ?", bomb.IdUser, bomb.TypeBomb).
+			UpdateColumn("amount", gorm.Expr("amount + 1"))
+		if refund.Error != nil {
+			return refund.Error
+		}
+		if refund.RowsAffected == 0 {
+			return tx.Create(&InventoryEntry{IDUser: bomb.IdUser, TypeBomb: bomb.TypeBomb, Amount: 1}).Error
+		}
+		return nil
+	})
+}
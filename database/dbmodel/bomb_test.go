@@ -0,0 +1,124 @@
+package dbmodel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+// Fresh in-memory database holding the tables of the models, shared by the connections of the test
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func inventoryAmount(t *testing.T, db *gorm.DB, idUser uuid.UUID, typeBomb string) int {
+	t.Helper()
+	var entry InventoryEntry
+	err := db.Where("id_user = ? AND type_bomb = ?", idUser, typeBomb).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return -1
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry.Amount
+}
+
+func TestBombCreateThrottle(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	// Bomb placed by the player before the new one
+	type previous struct {
+		typeBomb   string
+		ago        time.Duration
+		detonateIn time.Duration // Negative for an overdue bomb
+		status     BombStatus
+		otherGame  bool
+	}
+	classic := func(ago, detonateIn time.Duration) previous {
+		return previous{typeBomb: "classic", ago: ago, detonateIn: detonateIn, status: BombStatusArmed}
+	}
+
+	tests := []struct {
+		name     string
+		ruleset  rules.Ruleset
+		previous []previous
+		typeBomb string
+		want     ThrottleReason // Empty when the bomb is placed
+		retryAt  time.Time
+	}{
+		{"first bomb", rules.Ruleset{PlacementCooldown: 10}, nil, "classic", "", time.Time{}},
+		{"no cooldown set", rules.Ruleset{}, []previous{classic(0, 30*time.Second)}, "classic", "", time.Time{}},
+		{"placement cooldown", rules.Ruleset{PlacementCooldown: 10}, []previous{classic(3*time.Second, 30*time.Second)}, "classic", ThrottleCooldown, now.Add(7 * time.Second)},
+		{"placement cooldown over", rules.Ruleset{PlacementCooldown: 10}, []previous{classic(11*time.Second, 30*time.Second)}, "classic", "", time.Time{}},
+		{"bombs of other games", rules.Ruleset{PlacementCooldown: 10}, []previous{{typeBomb: "classic", ago: time.Second, detonateIn: time.Minute, status: BombStatusArmed, otherGame: true}}, "classic", "", time.Time{}},
+		{"type cooldown", rules.Ruleset{PlacementCooldown: 10, TypeCooldowns: map[string]int{"classic": 60}}, []previous{classic(20*time.Second, 10*time.Second)}, "classic", ThrottleTypeCooldown, now.Add(40 * time.Second)},
+		{"type cooldown of another type", rules.Ruleset{TypeCooldowns: map[string]int{"classic": 60}}, []previous{{typeBomb: "giant", ago: 20 * time.Second, detonateIn: time.Minute, status: BombStatusArmed}}, "classic", "", time.Time{}},
+		{"latest limit wins", rules.Ruleset{PlacementCooldown: 30, TypeCooldowns: map[string]int{"classic": 20}}, []previous{classic(5*time.Second, time.Minute)}, "classic", ThrottleCooldown, now.Add(25 * time.Second)},
+		{"max active bombs", rules.Ruleset{MaxActiveBombs: 2}, []previous{classic(time.Minute, 40*time.Second), classic(50*time.Second, 20*time.Second)}, "classic", ThrottleActiveBombs, now.Add(20 * time.Second)},
+		{"overdue active bomb", rules.Ruleset{MaxActiveBombs: 1}, []previous{classic(time.Minute, -time.Second)}, "classic", ThrottleActiveBombs, now.Add(rules.MinCooldown)},
+		{"exploded bombs free their slot", rules.Ruleset{MaxActiveBombs: 1}, []previous{{typeBomb: "classic", ago: time.Minute, detonateIn: -30 * time.Second, status: BombStatusDetonated}}, "classic", "", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &BombEntry{}, &InventoryEntry{})
+			repository := NewBombRepository(db)
+			idGame, idUser := uuid.New(), uuid.New()
+			if err := db.Create(&InventoryEntry{IDUser: idUser, TypeBomb: tt.typeBomb, Amount: 5}).Error; err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range tt.previous {
+				bomb := &BombEntry{TypeBomb: p.typeBomb, IdUser: idUser, IDGame: idGame, PlacedAt: now.Add(-p.ago), DetonateAt: now.Add(p.detonateIn), Status: p.status}
+				if p.otherGame {
+					bomb.IDGame = uuid.New()
+				}
+				if err := db.Omit("Game").Create(bomb).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			bomb := &BombEntry{TypeBomb: tt.typeBomb, IdUser: idUser, IDGame: idGame, PlacedAt: now, DetonateAt: now.Add(30 * time.Second), Status: BombStatusArmed}
+			_, err := repository.Create(bomb, &tt.ruleset)
+
+			wantAmount := 4
+			var throttle *ThrottleError
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("Create() error = %v, want the bomb placed", err)
+			case tt.want == "":
+			case !errors.As(err, &throttle):
+				t.Fatalf("Create() error = %v, want a %s throttle", err, tt.want)
+			default:
+				wantAmount = 5
+				if throttle.Reason != tt.want || !throttle.RetryAt.Equal(tt.retryAt) {
+					t.Errorf("Create() throttled by %s until %s, want %s until %s", throttle.Reason, throttle.RetryAt, tt.want, tt.retryAt)
+				}
+			}
+
+			// A refused bomb is not paid for
+			if got := inventoryAmount(t, db, idUser, tt.typeBomb); got != wantAmount {
+				t.Errorf("inventory = %d, want %d", got, wantAmount)
+			}
+		})
+	}
+}
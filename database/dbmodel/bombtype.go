@@ -0,0 +1,137 @@
+package dbmodel
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type BombRarity string
+
+const (
+	BombRarityCommon    BombRarity = "common"
+	BombRarityRare      BombRarity = "rare"
+	BombRarityEpic      BombRarity = "epic"
+	BombRarityLegendary BombRarity = "legendary"
+)
+
+// What makes a bomb explode
+type BombBehaviour string
+
+const (
+	BombBehaviourTimed BombBehaviour = "timed" // Explodes once its fuse has burnt
+	BombBehaviourMine  BombBehaviour = "mine"  // Explodes when an opponent comes within its trigger radius, expires once its fuse has burnt
+)
+
+// Widest trigger radius a mine can have, in meters
+const MaxTriggerRadius = 50
+
+var ErrBombTypeInUse = errors.New("bomb type is used by placed bombs")
+
+// Kind of bomb players can hold and place, bombs and inventories refer to it by TypeBomb
+type BombTypeEntry struct {
+	TypeBomb string  `gorm:"type:varchar(32);primaryKey"`
+	Name     string  `gorm:"type:varchar(64)"`
+	Radius   float64 // Meters
+	Damage   int
+	Fuse     int        // Default seconds between placement and detonation
+	Rarity   BombRarity `gorm:"type:varchar(16)"`
+	Enabled  bool       // Disabled types can't be placed or handed out anymore
+
+	Behaviour     BombBehaviour `gorm:"type:varchar(16)"`
+	TriggerRadius float64       // Meters, mines only
+
+	CrudInfo
+}
+
+// Bomb types the registry starts with, missing ones are registered again on startup
+var DefaultBombTypes = []BombTypeEntry{
+	{TypeBomb: "classic", Name: "Classic", Radius: 25, Damage: 10, Fuse: 30, Rarity: BombRarityCommon, Enabled: true, Behaviour: BombBehaviourTimed},
+	{TypeBomb: "double", Name: "Double", Radius: 25, Damage: 20, Fuse: 30, Rarity: BombRarityRare, Enabled: true, Behaviour: BombBehaviourTimed},
+	{TypeBomb: "giant", Name: "Giant", Radius: 75, Damage: 30, Fuse: 60, Rarity: BombRarityEpic, Enabled: true, Behaviour: BombBehaviourTimed},
+	{TypeBomb: "mine", Name: "Mine", Radius: 15, Damage: 20, Fuse: 900, Rarity: BombRarityRare, Enabled: true, Behaviour: BombBehaviourMine, TriggerRadius: 5},
+}
+
+type BombTypeRepository interface {
+	Create(entry *BombTypeEntry) (*BombTypeEntry, error)
+	FindAll() ([]*BombTypeEntry, error)
+	FindByType(typeBomb string) (*BombTypeEntry, error)
+	Types() ([]string, error)
+	Update(entry *BombTypeEntry) (*BombTypeEntry, error)
+	Delete(typeBomb string) error
+}
+
+type bombTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewBombTypeRepository(db *gorm.DB) BombTypeRepository {
+	return &bombTypeRepository{db: db}
+}
+
+func (r *bombTypeRepository) Create(entry *BombTypeEntry) (*BombTypeEntry, error) {
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *bombTypeRepository) FindAll() ([]*BombTypeEntry, error) {
+	var entries []*BombTypeEntry
+	if err := r.db.Order("type_bomb").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *bombTypeRepository) FindByType(typeBomb string) (*BombTypeEntry, error) {
+	var entry BombTypeEntry
+	if err := r.db.Where("type_bomb = ?", typeBomb).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Every registered type, enabled or not
+func (r *bombTypeRepository) Types() ([]string, error) {
+	var types []string
+	if err := r.db.Model(&BombTypeEntry{}).Order("type_bomb").Pluck("type_bomb", &types).Error; err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+func (r *bombTypeRepository) Update(entry *BombTypeEntry) (*BombTypeEntry, error) {
+	// Selected so that disabling a type is not skipped as a zero value
+	if err := r.db.Model(entry).
+		Select("name", "radius", "damage", "fuse", "rarity", "enabled", "behaviour", "trigger_radius").
+		Updates(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Remove a type no bomb was ever placed with, the others can only be disabled
+func (r *bombTypeRepository) Delete(typeBomb string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var placed int64
+		if err := tx.Model(&BombEntry{}).Where("type_bomb = ?", typeBomb).Count(&placed).Error; err != nil {
+			return err
+		}
+		if placed > 0 {
+			return ErrBombTypeInUse
+		}
+
+		if err := tx.Where("type_bomb = ?", typeBomb).Delete(&InventoryEntry{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("type_bomb = ?", typeBomb).Delete(&BombTypeEntry{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attempt of a player to defuse an opposing bomb, confirmed once ReadyAt has passed
+type DefusalEntry struct {
+	IDDefusal   int       `gorm:"primaryKey"`
+	IDBomb      int       `gorm:"index:idx_defusal_player,priority:1"`
+	IDUser      uuid.UUID `gorm:"type:uuid;index:idx_defusal_player,priority:2"`
+	IDTeam      uuid.UUID `gorm:"type:uuid"` // Team of the player when they started
+	StartedAt   time.Time
+	ReadyAt     time.Time
+	ConfirmedAt *time.Time
+
+	CrudInfo
+}
+
+type DefusalRepository interface {
+	Start(entry *DefusalEntry) (*DefusalEntry, error)
+	FindPending(idBomb int, idUser uuid.UUID) (*DefusalEntry, error)
+	Confirm(entry *DefusalEntry, at time.Time, score *ScoreEventEntry) (*DefusalEntry, error)
+}
+
+type defusalRepository struct {
+	db *gorm.DB
+}
+
+func NewDefusalRepository(db *gorm.DB) DefusalRepository {
+	return &defusalRepository{db: db}
+}
+
+func (r *defusalRepository) Start(entry *DefusalEntry) (*DefusalEntry, error) {
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Defusal started by the player on the bomb and not confirmed yet
+func (r *defusalRepository) FindPending(idBomb int, idUser uuid.UUID) (*DefusalEntry, error) {
+	var entry DefusalEntry
+	if err := r.db.Where("id_bomb = ? AND id_user = ? AND confirmed_at IS NULL", idBomb, idUser).
+		Order("started_at DESC").
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Mark the bomb as defused by the player and credit their team, in one transaction
+func (r *defusalRepository) Confirm(entry *DefusalEntry, at time.Time, score *ScoreEventEntry) (*DefusalEntry, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+
+		// The bomb may have exploded or been defused by someone else in the meantime
+		result := tx.Model(&BombEntry{}).
+			Where("bomb_id = ? AND status = ?", entry.IDBomb, BombStatusArmed).
+			Updates(map[string]interface{}{"status": BombStatusDefused, "defused_by": entry.IDUser, "defused_at": at})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBombNotArmed
+		}
+
+		entry.ConfirmedAt = &at
+		if err := tx.Model(entry).Update("confirmed_at", at).Error; err != nil {
+			return err
+		}
+
+		if score == nil {
+			return nil
+		}
+		return addScore(tx, score)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,93 @@
+package dbmodel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrBombNotArmed = errors.New("bomb is not armed")
+
+type DetonationEntry struct {
+	IDDetonation int        `gorm:"primaryKey" json:"id_detonation"`
+	IDBomb       int        `gorm:"index" json:"id_bomb"`
+	IDGame       *uuid.UUID `gorm:"type:uuid;index" json:"id_game"`
+	IDTeam       *uuid.UUID `gorm:"type:uuid" json:"id_team"` // Team credited with the points
+	DetonatedAt  time.Time  `json:"detonated_at"`
+	Lat          float32    `json:"lat"`
+	Long         float32    `json:"long"`
+	Radius       float64    `json:"radius"`
+	Damage       int        `json:"damage"`
+	Points       int        `json:"points"`
+	Hits         int        `json:"hits"`                        // Players caught in the blast, when the ruleset scores hits
+	IDChain      int        `gorm:"index" json:"id_chain"`       // Bomb whose fuse started the chain reaction
+	IDTrigger    *int       `json:"id_trigger"`                  // Bomb whose blast set this one off, nil for the start of the chain
+	Hop          int        `json:"hop"`                         // Blasts between the start of the chain and this one
+	TrippedBy    *uuid.UUID `gorm:"type:uuid" json:"tripped_by"` // Opponent who walked into the mine
+
+	CrudInfo
+}
+
+type DetonationRepository interface {
+	Record(entry *DetonationEntry, score *ScoreEventEntry) (*DetonationEntry, error)
+	FindByBomb(idBomb int) (*DetonationEntry, error)
+	FindChain(idChain int) ([]*DetonationEntry, error)
+}
+
+type detonationRepository struct {
+	db *gorm.DB
+}
+
+func NewDetonationRepository(db *gorm.DB) DetonationRepository {
+	return &detonationRepository{db: db}
+}
+
+// Mark the bomb as detonated, store the detonation and credit the team with the score, if any, in one transaction
+func (r *detonationRepository) Record(entry *DetonationEntry, score *ScoreEventEntry) (*DetonationEntry, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+
+		// Only an armed bomb can detonate, whoever gets there first wins
+		result := tx.Model(&BombEntry{}).
+			Where("bomb_id = ? AND status = ?", entry.IDBomb, BombStatusArmed).
+			Update("status", BombStatusDetonated)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBombNotArmed
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		if score == nil {
+			return nil
+		}
+		return addScore(tx, score)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *detonationRepository) FindByBomb(idBomb int) (*DetonationEntry, error) {
+	var entry DetonationEntry
+	if err := r.db.Where("id_bomb = ?", idBomb).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Detonations of a chain reaction in the order the bombs exploded
+func (r *detonationRepository) FindChain(idChain int) ([]*DetonationEntry, error) {
+	var entries []*DetonationEntry
+	if err := r.db.Where("id_chain = ?", idChain).Order("hop, id_detonation").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
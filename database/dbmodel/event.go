@@ -0,0 +1,68 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GameEventType string
+
+const (
+	EventBombPlaced    GameEventType = "bomb.placed"
+	EventBombMoved     GameEventType = "bomb.moved"
+	EventBombRemoved   GameEventType = "bomb.removed"
+	EventBombDetonated GameEventType = "bomb.detonated"
+	EventBombDefused   GameEventType = "bomb.defused"
+	EventScoreChanged  GameEventType = "score.changed"
+	EventPlayerJoined  GameEventType = "player.joined"
+	EventTeamsBalanced GameEventType = "teams.rebalanced"
+	EventStatusChanged GameEventType = "game.status_changed"
+)
+
+// Something that happened during a game. Events are only ever appended
+type GameEventEntry struct {
+	IDEvent uint                   `gorm:"primaryKey;autoIncrement"`
+	IDGame  uuid.UUID              `gorm:"type:uuid;index:idx_event_game,priority:1"`
+	At      time.Time              `gorm:"index:idx_event_game,priority:2"`
+	Type    GameEventType          `gorm:"type:varchar(32)"`
+	IDActor *uuid.UUID             `gorm:"type:uuid"` // User behind the event, none for the server
+	Data    map[string]interface{} `gorm:"type:text;serializer:json"`
+
+	CreatedAt time.Time
+}
+
+func (e *GameEventEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	// Stored in UTC for the time ranges to compare instants
+	e.At = e.At.UTC()
+	return
+}
+
+type GameEventRepository interface {
+	Append(entry *GameEventEntry) error
+	FindByGame(idGame uuid.UUID, from, to time.Time) ([]*GameEventEntry, error)
+}
+
+type gameEventRepository struct {
+	db *gorm.DB
+}
+
+func NewGameEventRepository(db *gorm.DB) GameEventRepository {
+	return &gameEventRepository{db: db}
+}
+
+func (r *gameEventRepository) Append(entry *GameEventEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// Events of the game in [from, to], in the order they happened
+func (r *gameEventRepository) FindByGame(idGame uuid.UUID, from, to time.Time) ([]*GameEventEntry, error) {
+	var entries []*GameEventEntry
+	if err := r.db.Where("id_game = ? AND at >= ? AND at <= ?", idGame, from.UTC(), to.UTC()).
+		Order("at, id_event").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
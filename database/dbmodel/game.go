@@ -1,34 +1,254 @@
 package dbmodel
 
 import (
+	"cmp"
+	"crypto/rand"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/pkg/balance"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/hexgrid"
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+type GameStatus string
+
+const (
+	GameStatusDraft     GameStatus = "draft"
+	GameStatusScheduled GameStatus = "scheduled"
+	GameStatusRunning   GameStatus = "running"
+	GameStatusPaused    GameStatus = "paused"
+	GameStatusFinished  GameStatus = "finished"
+)
+
+type GameVisibility string
+
+const (
+	GameVisibilityPublic   GameVisibility = "public"   // Listed and found nearby by everyone
+	GameVisibilityUnlisted GameVisibility = "unlisted" // Reached by its id or join code only
+	GameVisibilityPrivate  GameVisibility = "private"  // Hidden from everyone but its host and players
+)
+
+type GameMode string
+
+const (
+	GameModeClassic   GameMode = "classic"
+	GameModeTerritory GameMode = "territory"
+)
+
+// Territory cells are a tenth of the game radius wide
+const territoryCellRatio = 10
+
+// Largest radius of a play area in meters, as accepted by the game requests
+const maxGameSize = 10107
+
+type gameTransition struct {
+	from []GameStatus
+	to   GameStatus
+}
+
+// Lifecycle actions and the statuses they can be applied from
+var gameTransitions = map[string]gameTransition{
+	"schedule": {from: []GameStatus{GameStatusDraft}, to: GameStatusScheduled},
+	"start":    {from: []GameStatus{GameStatusDraft, GameStatusScheduled}, to: GameStatusRunning},
+	"pause":    {from: []GameStatus{GameStatusRunning}, to: GameStatusPaused},
+	"resume":   {from: []GameStatus{GameStatusPaused}, to: GameStatusRunning},
+	"finish":   {from: []GameStatus{GameStatusRunning, GameStatusPaused}, to: GameStatusFinished},
+}
+
+var ErrInvalidTransition = errors.New("invalid game status transition")
+
+// Join codes avoid the characters easily mistaken for one another
+const (
+	joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	joinCodeLength   = 6
 )
 
+func NewJoinCode() string {
+	code := make([]byte, joinCodeLength)
+	rand.Read(code)
+	for i, b := range code {
+		code[i] = joinCodeAlphabet[int(b)%len(joinCodeAlphabet)]
+	}
+	return string(code)
+}
+
 type GameEntry struct {
-	IDGame          uuid.UUID `gorm:"type:uuid;primaryKey"`
-	CenterLatitude  float32   `json:"center_latitude"`
-	CenterLongitude float32   `json:"center_longitude"`
-	Size            float32   `json:"size"`
-	StartingDate    time.Time `json:"starting_date"`
-	EndingDate      time.Time `json:"ending_date"`
-
-	Teams []TeamEntry `json:"teams" gorm:"foreignKey:IDTeam;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	IDGame          uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	CenterLatitude  float32        `json:"center_latitude"`
+	CenterLongitude float32        `json:"center_longitude"`
+	Size            float32        `json:"size"` // Radius of the play area in meters
+	StartingDate    time.Time      `json:"starting_date"`
+	EndingDate      time.Time      `json:"ending_date"`
+	Status          GameStatus     `gorm:"type:varchar(16);default:draft" json:"status"`
+	Mode            GameMode       `gorm:"type:varchar(16);default:classic" json:"mode"`
+	JoinCode        string         `gorm:"type:varchar(8);uniqueIndex" json:"join_code"`
+	IDHost          uuid.UUID      `gorm:"type:uuid;index" json:"id_host"` // User who created the game
+	BalanceStrategy string         `gorm:"type:varchar(16);default:size" json:"balance_strategy"`
+	Visibility      GameVisibility `gorm:"type:varchar(16);default:public;index" json:"visibility"`
+	Geohash         string         `gorm:"type:varchar(12);index" json:"-"` // Geohash of the center, see FindNearby
+
+	// Optional polygon replacing the circle as the play area boundary
+	Boundary       *geo.MultiPolygon  `gorm:"type:text;serializer:json" json:"boundary"`
+	ExclusionZones []geo.MultiPolygon `gorm:"type:text;serializer:json" json:"exclusion_zones"`
+
+	// Optional battle-royale zone shrinking from the game circle
+	ZoneSchedule *geo.ZoneSchedule `gorm:"type:text;serializer:json" json:"zone_schedule"`
+
+	Ruleset rules.Ruleset `gorm:"type:text;serializer:json" json:"ruleset"`
+
+	Teams []TeamEntry `json:"teams" gorm:"foreignKey:IDGame;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	CrudInfo
 }
 
 func (g *GameEntry) BeforeCreate(tx *gorm.DB) (err error) {
 	g.IDGame = uuid.New()
+	if g.Status == "" {
+		g.Status = GameStatusDraft
+	}
+	if g.Mode == "" {
+		g.Mode = GameModeClassic
+	}
+	if g.BalanceStrategy == "" {
+		g.BalanceStrategy = balance.Default
+	}
+	if g.Visibility == "" {
+		g.Visibility = GameVisibilityPublic
+	}
+	g.JoinCode = NewJoinCode()
+	g.Geohash = geo.Geohash(g.Circle().Center, geo.GeohashPrecision)
 	return
 }
 
+// Return the status reached by applying the lifecycle action to the game
+func (g *GameEntry) Transition(action string) (GameStatus, error) {
+	transition, ok := gameTransitions[action]
+	if !ok {
+		return "", ErrInvalidTransition
+	}
+	for _, from := range transition.from {
+		if g.Status == from {
+			return transition.to, nil
+		}
+	}
+	return "", ErrInvalidTransition
+}
+
+// Bombs can only be placed, moved or removed while the game is running
+func (g *GameEntry) AcceptsBombs() bool {
+	return g.Status == GameStatusRunning
+}
+
+// Scores only move once the game has started and until it is finished
+func (g *GameEntry) AcceptsScoreChanges() bool {
+	return g.Status == GameStatusRunning || g.Status == GameStatusPaused
+}
+
+// Inventories are frozen while the game is paused or finished
+func (g *GameEntry) AcceptsInventoryChanges() bool {
+	return g.Status != GameStatusPaused && g.Status != GameStatusFinished
+}
+
+// Players agree on the rules before the game starts
+func (g *GameEntry) AcceptsRuleChanges() bool {
+	return g.Status == GameStatusDraft || g.Status == GameStatusScheduled
+}
+
+func (g *GameEntry) Circle() geo.Circle {
+	return geo.Circle{
+		Center: geo.NewPoint(g.CenterLatitude, g.CenterLongitude),
+		Radius: float64(g.Size),
+	}
+}
+
+// Hexagonal grid of the territory mode, built on the game circle
+func (g *GameEntry) Grid() hexgrid.Grid {
+	return hexgrid.New(geo.NewPoint(g.CenterLatitude, g.CenterLongitude), float64(g.Size)/territoryCellRatio)
+}
+
+// Zone in force at the given moment, the game circle if the game has no zone schedule
+func (g *GameEntry) ZoneAt(at time.Time) geo.ZoneState {
+	if g.ZoneSchedule == nil {
+		return geo.ZoneState{Current: g.Circle()}
+	}
+	return g.ZoneSchedule.At(g.StartingDate, g.Circle(), at)
+}
+
+// Play area at the given moment. The circle is the boundary unless a polygon
+// has been given, and the zone in force restricts it further
+func (g *GameEntry) PlayAreaAt(at time.Time) geo.PlayArea {
+	area := geo.PlayArea{Boundary: g.Circle()}
+	if g.Boundary != nil {
+		area.Boundary = *g.Boundary
+	}
+	if g.ZoneSchedule != nil {
+		area.Zone = g.ZoneAt(at).Current
+	}
+	for _, zone := range g.ExclusionZones {
+		area.Exclusions = append(area.Exclusions, zone)
+	}
+
+	return area
+}
+
+// Whether the game left the draft and scheduled statuses
+func (g *GameEntry) HasStarted() bool {
+	return g.Status != GameStatusDraft && g.Status != GameStatusScheduled
+}
+
+func (g *GameEntry) IsFinished() bool {
+	return g.Status == GameStatusFinished
+}
+
+// Whether the user hosts the game or plays in one of its teams, teams must be loaded
+func (g *GameEntry) IsMember(user *UserEntry) bool {
+	if g.IDHost == user.IDUser {
+		return true
+	}
+	if user.IDTeam == nil {
+		return false
+	}
+	for _, team := range g.Teams {
+		if team.IDTeam == *user.IDTeam {
+			return true
+		}
+	}
+	return false
+}
+
+// Private games only exist for their members and the admins
+func (g *GameEntry) VisibleTo(user *UserEntry) bool {
+	return g.Visibility != GameVisibilityPrivate || user.Role == UserRoleAdmin || g.IsMember(user)
+}
+
+// The host and the admins manage the game
+func (g *GameEntry) ManagedBy(user *UserEntry) bool {
+	return g.IDHost == user.IDUser || user.Role == UserRoleAdmin
+}
+
+// Meters between the point and the play area circle, 0 inside it
+func (g *GameEntry) DistanceTo(p geo.Point) float64 {
+	return max(geo.Distance(g.Circle().Center, p)-float64(g.Size), 0)
+}
+
 type GameRepository interface {
 	Create(entry *GameEntry) (*GameEntry, error)
 	FindById(id uuid.UUID) (*GameEntry, error)
 	FindAll() ([]*GameEntry, error)
+	FindListed(user *UserEntry) ([]*GameEntry, error)
+	FindNearby(circle geo.Circle) ([]*GameEntry, error)
+	FindByUserId(idUser uuid.UUID) (*GameEntry, error)
+	FindByStatus(status GameStatus) ([]*GameEntry, error)
+	FindExpired(now time.Time) ([]*GameEntry, error)
+	FindByJoinCode(code string) (*GameEntry, error)
 	Update(entry *GameEntry, id uuid.UUID) (*GameEntry, error)
+	UpdateStatus(id uuid.UUID, from, to GameStatus) error
+	RegenerateJoinCode(id uuid.UUID) (string, error)
 	DeleteById(id uuid.UUID) error
 }
 
@@ -40,6 +260,7 @@ func NewGameRepository(db *gorm.DB) GameRepository {
 	return &gameRepository{db: db}
 }
 
+// Create the game, the teams it holds are inserted in the same transaction
 func (r *gameRepository) Create(entry *GameEntry) (*GameEntry, error) {
 
 	if err := r.db.Create(entry).Error; err != nil {
@@ -73,18 +294,127 @@ func (r *gameRepository) FindAll() ([]*GameEntry, error) {
 	return entries, nil
 }
 
+// Public games along with the games the user hosts or plays in
+func (r *gameRepository) FindListed(user *UserEntry) ([]*GameEntry, error) {
+
+	query := r.db.Model(&GameEntry{}).Preload("Teams")
+	if user.IDTeam != nil {
+		query = query.Where("visibility = ? OR id_host = ? OR id_game IN (?)", GameVisibilityPublic, user.IDUser,
+			r.db.Model(&TeamEntry{}).Select("id_game").Where("id_team = ?", *user.IDTeam))
+	} else {
+		query = query.Where("visibility = ? OR id_host = ?", GameVisibilityPublic, user.IDUser)
+	}
+
+	var entries []*GameEntry
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Upcoming and running public games whose play area reaches the circle, closest first then soonest.
+// Candidates are fetched from the geohash index then filtered on their exact distance.
+func (r *gameRepository) FindNearby(circle geo.Circle) ([]*GameEntry, error) {
+
+	// Centers of the play areas reaching the circle are at most a game size away from it
+	cover, args := geohashCover(geo.Circle{Center: circle.Center, Radius: circle.Radius + maxGameSize})
+
+	var candidates []*GameEntry
+	if err := r.db.Model(&GameEntry{}).
+		Preload("Teams").
+		Where("visibility = ? AND status <> ?", GameVisibilityPublic, GameStatusFinished).
+		Where(cover, args...).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	distances := map[uuid.UUID]float64{}
+	games := []*GameEntry{}
+	for _, game := range candidates {
+		distance := game.DistanceTo(circle.Center)
+		if distance <= circle.Radius {
+			distances[game.IDGame] = distance
+			games = append(games, game)
+		}
+	}
+	slices.SortFunc(games, func(a, b *GameEntry) int {
+		return cmp.Or(cmp.Compare(distances[a.IDGame], distances[b.IDGame]),
+			a.StartingDate.Compare(b.StartingDate),
+			cmp.Compare(a.IDGame.String(), b.IDGame.String()))
+	})
+
+	return games, nil
+}
+
+func (r *gameRepository) FindByUserId(idUser uuid.UUID) (*GameEntry, error) {
+
+	var entry GameEntry
+	if err := r.db.Model(&GameEntry{}).
+		Joins("JOIN team_entries ON team_entries.id_game = game_entries.id_game").
+		Joins("JOIN user_entries ON user_entries.id_team = team_entries.id_team").
+		Where("user_entries.id_user = ?", idUser).
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *gameRepository) FindByStatus(status GameStatus) ([]*GameEntry, error) {
+
+	var entries []*GameEntry
+	if err := r.db.Where("status = ?", status).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Games still running or paused past their ending date
+func (r *gameRepository) FindExpired(now time.Time) ([]*GameEntry, error) {
+
+	var entries []*GameEntry
+	if err := r.db.Where("status IN ?", []GameStatus{GameStatusRunning, GameStatusPaused}).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	// Dates keep the offset they were sent with, compare them as instants
+	expired := []*GameEntry{}
+	for _, entry := range entries {
+		if !entry.EndingDate.After(now) {
+			expired = append(expired, entry)
+		}
+	}
+
+	return expired, nil
+}
+
+func (r *gameRepository) FindByJoinCode(code string) (*GameEntry, error) {
+
+	var entry GameEntry
+	if err := r.db.Model(&GameEntry{}).
+		Preload("Teams").
+		Where("join_code = ?", code).
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
 func (r *gameRepository) Update(entry *GameEntry, id uuid.UUID) (*GameEntry, error) {
 
+	// The center may have moved
+	entry.Geohash = geo.Geohash(entry.Circle().Center, geo.GeohashPrecision)
+
+	// Updated from the struct so the geometries go through their serializer
 	result := r.db.Model(&GameEntry{}).
 		Where("id_game = ?", id).
-		Omit("Teams").
-		Updates(map[string]interface{}{
-			"center_latitude":  entry.CenterLatitude,
-			"center_longitude": entry.CenterLongitude,
-			"size":             entry.Size,
-			"starting_date":    entry.StartingDate,
-			"ending_date":      entry.EndingDate,
-		})
+		Select("center_latitude", "center_longitude", "size", "starting_date", "ending_date",
+			"mode", "balance_strategy", "visibility", "geohash", "boundary", "exclusion_zones", "zone_schedule", "ruleset").
+		Updates(entry)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -99,11 +429,85 @@ func (r *gameRepository) Update(entry *GameEntry, id uuid.UUID) (*GameEntry, err
 
 }
 
+func (r *gameRepository) UpdateStatus(id uuid.UUID, from, to GameStatus) error {
+
+	// Only update if nobody changed the status in the meantime
+	result := r.db.Model(&GameEntry{}).
+		Where("id_game = ? AND status = ?", id, from).
+		Update("status", to)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvalidTransition
+	}
+
+	return nil
+}
+
+// Replace the join code of the game, the previous one stops working
+func (r *gameRepository) RegenerateJoinCode(id uuid.UUID) (string, error) {
+
+	code := NewJoinCode()
+	result := r.db.Model(&GameEntry{}).Where("id_game = ?", id).Update("join_code", code)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+
+	return code, nil
+}
+
+// Delete the game along with everything recorded during it, its players leave its teams
 func (r *gameRepository) DeleteById(id uuid.UUID) error {
 
-	if err := r.db.Delete(GameEntry{}, id).Error; err != nil {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteGame(tx, id)
+	})
+}
+
+// Delete the game and what depends on it within the transaction, foreign keys don't cascade
+func deleteGame(tx *gorm.DB, id uuid.UUID) error {
+	if err := tx.Where("id_game = ?", id).Delete(&DetonationEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_bomb IN (?)", tx.Model(&BombEntry{}).Select("bomb_id").Where("id_game = ?", id)).
+		Delete(&DefusalEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_game = ?", id).Delete(&BombEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_game = ?", id).Delete(&PositionEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_game = ?", id).Delete(&TerritoryCellEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_game = ?", id).Delete(&GameEventEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_game = ?", id).Delete(&ScoreEventEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&GameResultEntry{}, id).Error; err != nil {
 		return err
 	}
 
-	return nil
+	// Players who joined the game leave it with its teams
+	teams := tx.Model(&TeamEntry{}).Select("id_team").Where("id_game = ?", id)
+	if err := tx.Model(&UserEntry{}).Where("id_team IN (?)", teams).
+		Updates(map[string]interface{}{"id_team": nil, "party": ""}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id_game = ?", id).Delete(&TeamEntry{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&GameEntry{}, id).Error
 }
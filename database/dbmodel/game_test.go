@@ -0,0 +1,100 @@
+package dbmodel
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGameTransition(t *testing.T) {
+	tests := []struct {
+		from    GameStatus
+		action  string
+		want    GameStatus
+		wantErr bool
+	}{
+		{GameStatusDraft, "schedule", GameStatusScheduled, false},
+		{GameStatusDraft, "start", GameStatusRunning, false},
+		{GameStatusScheduled, "start", GameStatusRunning, false},
+		{GameStatusRunning, "pause", GameStatusPaused, false},
+		{GameStatusPaused, "resume", GameStatusRunning, false},
+		{GameStatusRunning, "finish", GameStatusFinished, false},
+		{GameStatusPaused, "finish", GameStatusFinished, false},
+		{GameStatusScheduled, "schedule", "", true},
+		{GameStatusDraft, "pause", "", true},
+		{GameStatusRunning, "resume", "", true},
+		{GameStatusScheduled, "finish", "", true},
+		{GameStatusFinished, "start", "", true},
+		{GameStatusFinished, "finish", "", true},
+		{GameStatusRunning, "restart", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" "+tt.action, func(t *testing.T) {
+			game := &GameEntry{Status: tt.from}
+			got, err := game.Transition(tt.action)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Transition() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGameDeleteById(t *testing.T) {
+	db := newTestDB(t, &GameEntry{}, &TeamEntry{}, &UserEntry{}, &BombEntry{}, &DetonationEntry{}, &DefusalEntry{},
+		&PositionEntry{}, &TerritoryCellEntry{}, &GameEventEntry{}, &ScoreEventEntry{}, &GameResultEntry{})
+	create := func(value any) {
+		t.Helper()
+		if err := db.Create(value).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Fill the deleted game and another one with a player, a bomb and what they left behind
+	games := []*GameEntry{}
+	players := []*UserEntry{}
+	for i := 0; i < 2; i++ {
+		game := &GameEntry{Size: 500, Teams: []TeamEntry{{Name: "red"}}}
+		create(game)
+		team := game.Teams[0].IDTeam
+		player := &UserEntry{IDUser: uuid.New(), UserName: uuid.NewString(), Email: uuid.NewString(), IDTeam: &team, Party: "friends"}
+		create(player)
+		bomb := &BombEntry{IdUser: player.IDUser, IDGame: game.IDGame, IDTeam: team, Status: BombStatusDetonated}
+		if err := db.Omit("Game").Create(bomb).Error; err != nil {
+			t.Fatal(err)
+		}
+		create(&DetonationEntry{IDBomb: bomb.BombID, IDGame: &game.IDGame})
+		create(&DefusalEntry{IDBomb: bomb.BombID, IDUser: player.IDUser})
+		create(&PositionEntry{IDGame: game.IDGame, IDUser: player.IDUser})
+		create(&TerritoryCellEntry{IDGame: game.IDGame, IDTeam: team})
+		create(&GameEventEntry{IDGame: game.IDGame, Type: EventBombPlaced})
+		create(&ScoreEventEntry{IDGame: game.IDGame, IDTeam: team, Points: 10})
+		create(&GameResultEntry{IDGame: game.IDGame})
+		games = append(games, game)
+		players = append(players, player)
+	}
+
+	if err := NewGameRepository(db).DeleteById(games[0].IDGame); err != nil {
+		t.Fatalf("DeleteById() error = %v", err)
+	}
+
+	for _, model := range []any{&GameEntry{}, &TeamEntry{}, &BombEntry{}, &DetonationEntry{}, &DefusalEntry{},
+		&PositionEntry{}, &TerritoryCellEntry{}, &GameEventEntry{}, &ScoreEventEntry{}, &GameResultEntry{}} {
+		var count int64
+		if err := db.Model(model).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("%T rows left = %d, want the one of the other game", model, count)
+		}
+	}
+
+	for i, want := range []bool{false, true} {
+		var player UserEntry
+		if err := db.First(&player, "id_user = ?", players[i].IDUser).Error; err != nil {
+			t.Fatal(err)
+		}
+		if got := player.IDTeam != nil && player.Party != ""; got != want {
+			t.Errorf("player %d still in a team = %v, want %v", i, got, want)
+		}
+	}
+}
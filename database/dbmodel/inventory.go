@@ -1,8 +1,6 @@
 package dbmodel
 
 import (
-	"slices"
-
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -48,20 +46,28 @@ func (r *inventoryRepository) FindByUser(user UserEntry) ([]*InventoryEntry, err
 	return entries, nil
 }
 
+// Add the amount to the bombs of the type, negative to take some away, never going below none.
+// A single update so that bombs consumed by concurrent placements are not lost, the row of the types
+// registered after the inventory was initialised is created in the same transaction
 func (r *inventoryRepository) ChangeBombsAmount(user UserEntry, typeBomb string, amount int) (*InventoryEntry, error) {
-	userBombs, err := r.FindByUser(user)
-	if err != nil {
-		return nil, err
-	}
-
-	idx := slices.IndexFunc(userBombs, func(b *InventoryEntry) bool {
-		return b.TypeBomb == typeBomb
+	var entry InventoryEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		update := tx.Model(&InventoryEntry{}).
+			Where("id_user = ? AND type_bomb = ?", user.IDUser, typeBomb).
+			UpdateColumn("amount", gorm.Expr("MAX(amount + ?, 0)", amount))
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			entry = InventoryEntry{IDUser: user.IDUser, TypeBomb: typeBomb, Amount: max(amount, 0)}
+			return tx.Create(&entry).Error
+		}
+		return tx.Where("id_user = ? AND type_bomb = ?", user.IDUser, typeBomb).First(&entry).Error
 	})
-	userBombs[idx].Amount = max(userBombs[idx].Amount+amount, 0)
-	if err = r.db.Where("id_user = ? AND type_bomb = ?", user.IDUser, typeBomb).UpdateColumns(userBombs[idx]).Error; err != nil {
+	if err != nil {
 		return nil, err
 	}
-	return userBombs[idx], nil
+	return &entry, nil
 }
 
 func (r *inventoryRepository) AddNewBombType(user UserEntry, typeBomb string, startingAmount int) (*InventoryEntry, error) {
@@ -91,15 +97,18 @@ func (r *inventoryRepository) AddNewBombType(user UserEntry, typeBomb string, st
 	return bombType, nil
 }
 
+// Reset the inventory to none of every enabled bomb type of the registry
 func (r *inventoryRepository) InitUserInventory(user UserEntry) ([]*InventoryEntry, error) {
-	if _, err := r.AddNewBombType(user, "classic", 0); err != nil {
-		return nil, err
-	}
-	if _, err := r.AddNewBombType(user, "double", 0); err != nil {
+	var types []string
+	if err := r.db.Model(&BombTypeEntry{}).Where("enabled = ?", true).Order("type_bomb").
+		Pluck("type_bomb", &types).Error; err != nil {
 		return nil, err
 	}
-	if _, err := r.AddNewBombType(user, "giant", 0); err != nil {
-		return nil, err
+
+	for _, typeBomb := range types {
+		if _, err := r.AddNewBombType(user, typeBomb, 0); err != nil {
+			return nil, err
+		}
 	}
 	return r.FindByUser(user)
 }
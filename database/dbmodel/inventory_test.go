@@ -0,0 +1,141 @@
+package dbmodel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+func TestBombCreateConsumesInventory(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int // -1 when the inventory has no row for the type
+		wantErr    error
+		wantAmount int
+	}{
+		{"last bomb", 1, nil, 0},
+		{"several bombs", 3, nil, 2},
+		{"none left", 0, ErrNoBombLeft, 0},
+		{"type never handed out", -1, ErrNoBombLeft, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &BombEntry{}, &InventoryEntry{})
+			idUser := uuid.New()
+			if tt.amount >= 0 {
+				if err := db.Create(&InventoryEntry{IDUser: idUser, TypeBomb: "classic", Amount: tt.amount}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			bomb := &BombEntry{TypeBomb: "classic", IdUser: idUser, IDGame: uuid.New(), PlacedAt: time.Now(), Status: BombStatusArmed}
+			if _, err := NewBombRepository(db).Create(bomb, &rules.Ruleset{}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := inventoryAmount(t, db, idUser, "classic"); got != tt.wantAmount {
+				t.Errorf("inventory = %d, want %d", got, tt.wantAmount)
+			}
+
+			// A bomb that was not paid for is not placed
+			var placed int64
+			if err := db.Model(&BombEntry{}).Count(&placed).Error; err != nil {
+				t.Fatal(err)
+			}
+			want := int64(0)
+			if tt.wantErr == nil {
+				want = 1
+			}
+			if placed != want {
+				t.Errorf("%d bombs placed, want %d", placed, want)
+			}
+		})
+	}
+}
+
+func TestBombDeleteRefund(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     BombStatus
+		amount     int // -1 when the inventory has no row for the type
+		wantAmount int
+	}{
+		{"armed bomb given back", BombStatusArmed, 2, 3},
+		{"armed bomb of a type removed from the inventory", BombStatusArmed, -1, 1},
+		{"detonated bomb", BombStatusDetonated, 2, 2},
+		{"defused bomb", BombStatusDefused, 2, 2},
+		{"expired bomb", BombStatusExpired, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &BombEntry{}, &InventoryEntry{}, &DefusalEntry{})
+			idUser := uuid.New()
+			if tt.amount >= 0 {
+				if err := db.Create(&InventoryEntry{IDUser: idUser, TypeBomb: "classic", Amount: tt.amount}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			bomb := &BombEntry{TypeBomb: "classic", IdUser: idUser, IDGame: uuid.New(), PlacedAt: time.Now(), Status: tt.status}
+			if err := db.Omit("Game").Create(bomb).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Create(&DefusalEntry{IDBomb: bomb.BombID, IDUser: uuid.New()}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if err := NewBombRepository(db).Delete(bomb.BombID); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if got := inventoryAmount(t, db, idUser, "classic"); got != tt.wantAmount {
+				t.Errorf("inventory = %d, want %d", got, tt.wantAmount)
+			}
+			var left int64
+			if err := db.Model(&DefusalEntry{}).Count(&left).Error; err != nil {
+				t.Fatal(err)
+			}
+			if left != 0 {
+				t.Errorf("%d defusals left, want the ones of the bomb deleted", left)
+			}
+		})
+	}
+}
+
+func TestChangeBombsAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int // -1 when the inventory has no row for the type
+		change int
+		want   int
+	}{
+		{"add bombs", 2, 3, 5},
+		{"take bombs", 5, -2, 3},
+		{"never below none", 2, -5, 0},
+		{"new type", -1, 4, 4},
+		{"new type taken away", -1, -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &InventoryEntry{})
+			user := UserEntry{IDUser: uuid.New()}
+			if tt.amount >= 0 {
+				if err := db.Create(&InventoryEntry{IDUser: user.IDUser, TypeBomb: "classic", Amount: tt.amount}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entry, err := NewInventoryRepository(db).ChangeBombsAmount(user, "classic", tt.change)
+			if err != nil {
+				t.Fatalf("ChangeBombsAmount() error = %v", err)
+			}
+			if entry.Amount != tt.want {
+				t.Errorf("ChangeBombsAmount() = %d, want %d", entry.Amount, tt.want)
+			}
+			if got := inventoryAmount(t, db, user.IDUser, "classic"); got != tt.want {
+				t.Errorf("stored amount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
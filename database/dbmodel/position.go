@@ -0,0 +1,85 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GPS fix reported by a player during a game
+type PositionEntry struct {
+	IDPosition int       `gorm:"primaryKey"`
+	IDGame     uuid.UUID `gorm:"type:uuid;index:idx_position_track,priority:1"`
+	IDUser     uuid.UUID `gorm:"type:uuid;index:idx_position_track,priority:2"`
+	User       UserEntry `gorm:"foreignKey:IDUser;references:IDUser;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Lat        float32
+	Long       float32
+	Accuracy   float32    // Meters
+	RecordedAt time.Time  `gorm:"index:idx_position_track,priority:3"` // Reception by the server
+	ReportedAt *time.Time // Timestamp sent by the device, never trusted
+
+	CrudInfo
+}
+
+type PositionRepository interface {
+	Create(entry *PositionEntry) (*PositionEntry, error)
+	FindLast(idGame, idUser uuid.UUID) (*PositionEntry, error)
+	FindTrack(idGame, idUser uuid.UUID, from, to time.Time) ([]*PositionEntry, error)
+	FindLatest(idGame uuid.UUID, since time.Time) ([]*PositionEntry, error)
+}
+
+type positionRepository struct {
+	db *gorm.DB
+}
+
+func NewPositionRepository(db *gorm.DB) PositionRepository {
+	return &positionRepository{db: db}
+}
+
+func (r *positionRepository) Create(entry *PositionEntry) (*PositionEntry, error) {
+	if err := r.db.Omit("User").Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *positionRepository) FindLast(idGame, idUser uuid.UUID) (*PositionEntry, error) {
+	var entry PositionEntry
+	if err := r.db.Where("id_game = ? AND id_user = ?", idGame, idUser).
+		Order("recorded_at DESC").
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *positionRepository) FindTrack(idGame, idUser uuid.UUID, from, to time.Time) ([]*PositionEntry, error) {
+	var entries []*PositionEntry
+	if err := r.db.Where("id_game = ? AND id_user = ? AND recorded_at BETWEEN ? AND ?", idGame, idUser, from, to).
+		Order("recorded_at").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Last position of every player of the game who reported one since the given moment
+func (r *positionRepository) FindLatest(idGame uuid.UUID, since time.Time) ([]*PositionEntry, error) {
+	var entries []*PositionEntry
+	if err := r.db.Where("id_game = ? AND recorded_at >= ?", idGame, since).
+		Order("recorded_at").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	latest := map[uuid.UUID]*PositionEntry{}
+	for _, entry := range entries {
+		latest[entry.IDUser] = entry
+	}
+	res := make([]*PositionEntry, 0, len(latest))
+	for _, entry := range latest {
+		res = append(res, entry)
+	}
+	return res, nil
+}
@@ -0,0 +1,292 @@
+package dbmodel
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily  RecurrenceFrequency = "daily"
+	RecurrenceWeekly RecurrenceFrequency = "weekly"
+)
+
+type RecurrenceStatus string
+
+const (
+	RecurrenceStatusActive    RecurrenceStatus = "active"
+	RecurrenceStatusPaused    RecurrenceStatus = "paused"
+	RecurrenceStatusCancelled RecurrenceStatus = "cancelled"
+)
+
+type OccurrenceStatus string
+
+const (
+	OccurrenceStatusScheduled OccurrenceStatus = "scheduled"
+	OccurrenceStatusCancelled OccurrenceStatus = "cancelled"
+)
+
+var (
+	ErrOccurrenceExists  = errors.New("occurrence already materialised")
+	ErrOccurrenceStarted = errors.New("occurrence game already started")
+)
+
+// Games created from a template at a fixed time of day, every day or on some weekdays
+type RecurrenceEntry struct {
+	IDRecurrence uuid.UUID           `gorm:"type:uuid;primaryKey"`
+	IDOwner      uuid.UUID           `gorm:"type:uuid;index"`
+	IDTemplate   uuid.UUID           `gorm:"type:uuid;index"`
+	Frequency    RecurrenceFrequency `gorm:"type:varchar(16)"`
+	Weekdays     []time.Weekday      `gorm:"type:text;serializer:json"` // Weekly recurrences only
+	StartTime    string              `gorm:"type:varchar(5)"`           // "15:04" in the location of the recurrence
+	Location     string              `gorm:"type:varchar(64)"`          // IANA time zone
+	StartsOn     time.Time
+	EndsOn       *time.Time       // Last moment an occurrence can start at, none if it never ends
+	Status       RecurrenceStatus `gorm:"type:varchar(16);default:active;index"`
+	CrudInfo
+}
+
+func (r *RecurrenceEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	r.IDRecurrence = uuid.New()
+	if r.Status == "" {
+		r.Status = RecurrenceStatusActive
+	}
+	return
+}
+
+// Start dates of the occurrences in [from, to), in UTC
+func (r *RecurrenceEntry) Occurrences(from, to time.Time) []time.Time {
+	location, err := time.LoadLocation(r.Location)
+	if err != nil {
+		location = time.UTC
+	}
+	clock, err := time.Parse("15:04", r.StartTime)
+	if err != nil {
+		return nil
+	}
+
+	if from.Before(r.StartsOn) {
+		from = r.StartsOn
+	}
+
+	var occurrences []time.Time
+	first := from.In(location)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		// Built from the calendar day so the time of day survives daylight saving changes
+		at := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		if at.Before(from) || !at.Before(to) || (r.EndsOn != nil && at.After(*r.EndsOn)) {
+			continue
+		}
+		if r.Frequency == RecurrenceWeekly && !slices.Contains(r.Weekdays, at.Weekday()) {
+			continue
+		}
+		occurrences = append(occurrences, at.UTC())
+	}
+	return occurrences
+}
+
+// A game materialised from a recurrence. Cancelled occurrences are kept so
+// they are not materialised again
+type OccurrenceEntry struct {
+	IDOccurrence uuid.UUID        `gorm:"type:uuid;primaryKey"`
+	IDRecurrence uuid.UUID        `gorm:"type:uuid;uniqueIndex:idx_occurrence,priority:1"`
+	OccurrenceAt time.Time        `gorm:"uniqueIndex:idx_occurrence,priority:2"`
+	IDGame       *uuid.UUID       `gorm:"type:uuid"`
+	Status       OccurrenceStatus `gorm:"type:varchar(16);default:scheduled"`
+	CrudInfo
+}
+
+func (o *OccurrenceEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	o.IDOccurrence = uuid.New()
+	// Stored in UTC for the unique index to compare instants
+	o.OccurrenceAt = o.OccurrenceAt.UTC()
+	if o.Status == "" {
+		o.Status = OccurrenceStatusScheduled
+	}
+	return
+}
+
+type RecurrenceRepository interface {
+	Create(entry *RecurrenceEntry) (*RecurrenceEntry, error)
+	FindById(id uuid.UUID) (*RecurrenceEntry, error)
+	FindByOwner(idOwner uuid.UUID) ([]*RecurrenceEntry, error)
+	FindActive() ([]*RecurrenceEntry, error)
+	UpdateStatus(id uuid.UUID, from, to RecurrenceStatus) error
+	Cancel(id uuid.UUID, now time.Time) error
+	Delete(id uuid.UUID) error
+	Materialise(occurrence *OccurrenceEntry, game *GameEntry) error
+	FindOccurrence(idRecurrence, idOccurrence uuid.UUID) (*OccurrenceEntry, error)
+	FindUpcoming(idRecurrence uuid.UUID, now time.Time) ([]*OccurrenceEntry, error)
+	CancelOccurrence(occurrence *OccurrenceEntry) error
+}
+
+type recurrenceRepository struct {
+	db *gorm.DB
+}
+
+func NewRecurrenceRepository(db *gorm.DB) RecurrenceRepository {
+	return &recurrenceRepository{db: db}
+}
+
+func (r *recurrenceRepository) Create(entry *RecurrenceEntry) (*RecurrenceEntry, error) {
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *recurrenceRepository) FindById(id uuid.UUID) (*RecurrenceEntry, error) {
+	var entry RecurrenceEntry
+	if err := r.db.First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *recurrenceRepository) FindByOwner(idOwner uuid.UUID) ([]*RecurrenceEntry, error) {
+	var entries []*RecurrenceEntry
+	if err := r.db.Where("id_owner = ?", idOwner).Order("created_at").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *recurrenceRepository) FindActive() ([]*RecurrenceEntry, error) {
+	var entries []*RecurrenceEntry
+	if err := r.db.Where("status = ?", RecurrenceStatusActive).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Change the status only if nobody changed it in the meantime
+func (r *recurrenceRepository) UpdateStatus(id uuid.UUID, from, to RecurrenceStatus) error {
+	result := r.db.Model(&RecurrenceEntry{}).
+		Where("id_recurrence = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Cancel the recurrence along with its occurrences whose game has not started yet
+func (r *recurrenceRepository) Cancel(id uuid.UUID, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&RecurrenceEntry{}).Where("id_recurrence = ?", id).
+			Update("status", RecurrenceStatusCancelled).Error; err != nil {
+			return err
+		}
+
+		var upcoming []*OccurrenceEntry
+		if err := tx.Where("id_recurrence = ? AND status = ? AND occurrence_at > ?", id, OccurrenceStatusScheduled, now.UTC()).
+			Find(&upcoming).Error; err != nil {
+			return err
+		}
+		for _, occurrence := range upcoming {
+			// Games started early are left to their players
+			if err := cancelOccurrence(tx, occurrence); err != nil && !errors.Is(err, ErrOccurrenceStarted) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete the recurrence with its occurrences and their games
+func (r *recurrenceRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var occurrences []*OccurrenceEntry
+		if err := tx.Where("id_recurrence = ?", id).Find(&occurrences).Error; err != nil {
+			return err
+		}
+		for _, occurrence := range occurrences {
+			if occurrence.IDGame == nil {
+				continue
+			}
+			if err := deleteGame(tx, *occurrence.IDGame); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("id_recurrence = ?", id).Delete(&OccurrenceEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&RecurrenceEntry{}, id).Error
+	})
+}
+
+// Insert the occurrence and its game, unless the occurrence already exists
+func (r *recurrenceRepository) Materialise(occurrence *OccurrenceEntry, game *GameEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(occurrence)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOccurrenceExists
+		}
+
+		if err := tx.Create(game).Error; err != nil {
+			return err
+		}
+		occurrence.IDGame = &game.IDGame
+		return tx.Model(occurrence).Update("id_game", game.IDGame).Error
+	})
+}
+
+func (r *recurrenceRepository) FindOccurrence(idRecurrence, idOccurrence uuid.UUID) (*OccurrenceEntry, error) {
+	var entry OccurrenceEntry
+	if err := r.db.Where("id_recurrence = ?", idRecurrence).First(&entry, idOccurrence).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Occurrences starting after now, cancelled ones included
+func (r *recurrenceRepository) FindUpcoming(idRecurrence uuid.UUID, now time.Time) ([]*OccurrenceEntry, error) {
+	var entries []*OccurrenceEntry
+	if err := r.db.Where("id_recurrence = ? AND occurrence_at > ?", idRecurrence, now.UTC()).
+		Order("occurrence_at").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *recurrenceRepository) CancelOccurrence(occurrence *OccurrenceEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return cancelOccurrence(tx, occurrence)
+	})
+}
+
+// Remove the game of the occurrence if it has not started and mark the occurrence cancelled
+func cancelOccurrence(tx *gorm.DB, occurrence *OccurrenceEntry) error {
+	if occurrence.IDGame != nil {
+		var started int64
+		if err := tx.Model(&GameEntry{}).
+			Where("id_game = ? AND status NOT IN ?", *occurrence.IDGame, []GameStatus{GameStatusDraft, GameStatusScheduled}).
+			Count(&started).Error; err != nil {
+			return err
+		}
+		if started > 0 {
+			return ErrOccurrenceStarted
+		}
+
+		if err := deleteGame(tx, *occurrence.IDGame); err != nil {
+			return err
+		}
+	}
+
+	occurrence.Status = OccurrenceStatusCancelled
+	occurrence.IDGame = nil
+	return tx.Model(occurrence).Select("status", "id_game").Updates(occurrence).Error
+}
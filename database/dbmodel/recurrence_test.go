@@ -0,0 +1,194 @@
+package dbmodel
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	from := time.Date(2026, 3, 26, 0, 0, 0, 0, time.UTC) // A Thursday, the weekend before daylight saving in Europe
+	to := from.AddDate(0, 0, 5)
+	endsOn := time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)
+	utc := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		recurrence RecurrenceEntry
+		from, to   time.Time
+		want       []time.Time
+	}{
+		{
+			name:       "daily in utc",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "18:30", Location: "UTC"},
+			from:       from, to: to,
+			want: []time.Time{utc(26, 18, 30), utc(27, 18, 30), utc(28, 18, 30), utc(29, 18, 30), utc(30, 18, 30)},
+		},
+		{
+			name:       "weekly on the weekend",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceWeekly, Weekdays: []time.Weekday{time.Saturday, time.Sunday}, StartTime: "10:00", Location: "UTC"},
+			from:       from, to: to,
+			want: []time.Time{utc(28, 10, 0), utc(29, 10, 0)},
+		},
+		{
+			name:       "time of day kept across daylight saving",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "20:00", Location: "Europe/Paris"},
+			from:       from.AddDate(0, 0, 2), to: from.AddDate(0, 0, 4),
+			want: []time.Time{utc(28, 19, 0), utc(29, 18, 0)},
+		},
+		{
+			name:       "nothing before the start",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "18:30", Location: "UTC", StartsOn: utc(29, 0, 0)},
+			from:       from, to: to,
+			want: []time.Time{utc(29, 18, 30), utc(30, 18, 30)},
+		},
+		{
+			name:       "nothing after the end",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "09:00", Location: "UTC", EndsOn: &endsOn},
+			from:       from, to: to,
+			want: []time.Time{utc(26, 9, 0), utc(27, 9, 0), utc(28, 9, 0)},
+		},
+		{
+			name:       "range starting after the time of day",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "09:00", Location: "UTC"},
+			from:       utc(26, 10, 0), to: utc(27, 9, 0),
+			want: nil,
+		},
+		{
+			name:       "unknown time zone falls back to utc",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "12:00", Location: "Nowhere/City"},
+			from:       from, to: from.AddDate(0, 0, 1),
+			want: []time.Time{utc(26, 12, 0)},
+		},
+		{
+			name:       "invalid start time",
+			recurrence: RecurrenceEntry{Frequency: RecurrenceDaily, StartTime: "25:99", Location: "UTC"},
+			from:       from, to: to,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.recurrence.Occurrences(tt.from, tt.to)
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("Occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Recurrence with an occurrence materialised for each game status, a player joined the red team of each game
+func materialise(t *testing.T, db *gorm.DB, statuses ...GameStatus) (*RecurrenceEntry, []*OccurrenceEntry, []*UserEntry) {
+	t.Helper()
+	repository := NewRecurrenceRepository(db)
+	recurrence, err := repository.Create(&RecurrenceEntry{IDOwner: uuid.New(), Frequency: RecurrenceDaily, StartTime: "18:30", Location: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	occurrences, players := []*OccurrenceEntry{}, []*UserEntry{}
+	for i, status := range statuses {
+		occurrence := &OccurrenceEntry{IDRecurrence: recurrence.IDRecurrence, OccurrenceAt: time.Now().AddDate(0, 0, i+1)}
+		game := &GameEntry{Size: 500, Status: status, Teams: []TeamEntry{{Name: "red"}}}
+		if err := repository.Materialise(occurrence, game); err != nil {
+			t.Fatal(err)
+		}
+		player := &UserEntry{IDUser: uuid.New(), UserName: uuid.NewString(), Email: uuid.NewString(), IDTeam: &game.Teams[0].IDTeam, Party: "friends"}
+		if err := db.Create(player).Error; err != nil {
+			t.Fatal(err)
+		}
+		occurrences, players = append(occurrences, occurrence), append(players, player)
+	}
+	return recurrence, occurrences, players
+}
+
+func countRows(t *testing.T, db *gorm.DB, model any, query string, args ...any) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(model).Where(query, args...).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestCancelOccurrence(t *testing.T) {
+	tests := []struct {
+		status  GameStatus
+		wantErr error
+	}{
+		{GameStatusDraft, nil},
+		{GameStatusScheduled, nil},
+		{GameStatusRunning, ErrOccurrenceStarted},
+		{GameStatusFinished, ErrOccurrenceStarted},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			db := newTestDB(t, &RecurrenceEntry{}, &OccurrenceEntry{}, &GameEntry{}, &TeamEntry{}, &UserEntry{}, &BombEntry{},
+				&DetonationEntry{}, &DefusalEntry{}, &PositionEntry{}, &TerritoryCellEntry{}, &GameEventEntry{}, &ScoreEventEntry{}, &GameResultEntry{})
+			_, occurrences, players := materialise(t, db, tt.status)
+			occurrence := occurrences[0]
+			idGame := *occurrence.IDGame
+
+			err := NewRecurrenceRepository(db).CancelOccurrence(occurrence)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CancelOccurrence() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// A cancelled occurrence takes its game, the teams of the game and the places of its players with it
+			cancelled := tt.wantErr == nil
+			var stored OccurrenceEntry
+			if err := db.First(&stored, occurrence.IDOccurrence).Error; err != nil {
+				t.Fatal(err)
+			}
+			if (stored.Status == OccurrenceStatusCancelled && stored.IDGame == nil) != cancelled {
+				t.Errorf("occurrence %s of game %v, cancelled = %t", stored.Status, stored.IDGame, cancelled)
+			}
+			want := int64(1)
+			if cancelled {
+				want = 0
+			}
+			if games, teams := countRows(t, db, &GameEntry{}, "id_game = ?", idGame), countRows(t, db, &TeamEntry{}, "id_game = ?", idGame); games != want || teams != want {
+				t.Errorf("%d games and %d teams left, want %d", games, teams, want)
+			}
+			if joined := countRows(t, db, &UserEntry{}, "id_user = ? AND id_team IS NOT NULL", players[0].IDUser); joined != want {
+				t.Errorf("%d players still in the team, want %d", joined, want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceDelete(t *testing.T) {
+	db := newTestDB(t, &RecurrenceEntry{}, &OccurrenceEntry{}, &GameEntry{}, &TeamEntry{}, &UserEntry{}, &BombEntry{},
+		&DetonationEntry{}, &DefusalEntry{}, &PositionEntry{}, &TerritoryCellEntry{}, &GameEventEntry{}, &ScoreEventEntry{}, &GameResultEntry{})
+	deleted, occurrences, _ := materialise(t, db, GameStatusScheduled, GameStatusScheduled)
+	kept, _, _ := materialise(t, db, GameStatusScheduled)
+
+	if err := NewRecurrenceRepository(db).Delete(deleted.IDRecurrence); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if left := countRows(t, db, &RecurrenceEntry{}, "id_recurrence = ?", deleted.IDRecurrence); left != 0 {
+		t.Errorf("%d deleted recurrences left", left)
+	}
+	if left := countRows(t, db, &OccurrenceEntry{}, "id_recurrence = ?", deleted.IDRecurrence); left != 0 {
+		t.Errorf("%d occurrences of the deleted recurrence left", left)
+	}
+	idGames := []uuid.UUID{*occurrences[0].IDGame, *occurrences[1].IDGame}
+	if games, teams := countRows(t, db, &GameEntry{}, "id_game IN ?", idGames), countRows(t, db, &TeamEntry{}, "id_game IN ?", idGames); games != 0 || teams != 0 {
+		t.Errorf("%d games and %d teams of the deleted recurrence left", games, teams)
+	}
+
+	// The other recurrence is untouched
+	if left := countRows(t, db, &OccurrenceEntry{}, "id_recurrence = ? AND id_game IS NOT NULL", kept.IDRecurrence); left != 1 {
+		t.Errorf("%d occurrences of the other recurrence left, want 1", left)
+	}
+	if games := countRows(t, db, &GameEntry{}, "id_game NOT IN ?", idGames); games != 1 {
+		t.Errorf("%d games of the other recurrence left, want 1", games)
+	}
+}
@@ -0,0 +1,280 @@
+package dbmodel
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Standing of a team when the game ended
+type ResultTeam struct {
+	IDTeam uuid.UUID `json:"id_team"`
+	Name   string    `json:"name"`
+	Color  string    `json:"color"`
+	Score  int       `json:"score"`
+	Rank   int       `json:"rank"` // Teams with the same score share their rank
+}
+
+// What a player brought to their team
+type ResultPlayer struct {
+	IDUser   uuid.UUID      `json:"id_user"`
+	UserName string         `json:"user_name"`
+	IDTeam   uuid.UUID      `json:"id_team"`
+	Points   int            `json:"points"`
+	Bombs    map[string]int `json:"bombs"` // Bombs placed per type
+}
+
+// Official result of a finished game, never changed once written
+type GameResultEntry struct {
+	IDGame      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FinishedAt  time.Time
+	Duration    int            // Seconds the game was running, pauses excluded
+	Teams       []ResultTeam   `gorm:"type:text;serializer:json"`
+	Players     []ResultPlayer `gorm:"type:text;serializer:json"`
+	BombsByType map[string]int `gorm:"type:text;serializer:json"`
+
+	CreatedAt time.Time
+}
+
+type GameResultRepository interface {
+	Finish(idGame uuid.UUID, from GameStatus, at time.Time) (*GameResultEntry, error)
+	Snapshot(idGame uuid.UUID, at time.Time) (*GameResultEntry, error)
+	FindByGame(idGame uuid.UUID) (*GameResultEntry, error)
+}
+
+type gameResultRepository struct {
+	db *gorm.DB
+}
+
+func NewGameResultRepository(db *gorm.DB) GameResultRepository {
+	return &gameResultRepository{db: db}
+}
+
+// Finish the game and freeze its result in one transaction
+func (r *gameResultRepository) Finish(idGame uuid.UUID, from GameStatus, at time.Time) (*GameResultEntry, error) {
+	var result *GameResultEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+
+		// Only update if nobody changed the status in the meantime
+		update := tx.Model(&GameEntry{}).
+			Where("id_game = ? AND status = ?", idGame, from).
+			Update("status", GameStatusFinished)
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return ErrInvalidTransition
+		}
+
+		// Nothing explodes in a finished game
+		if err := tx.Model(&BombEntry{}).
+			Where("id_game = ? AND status = ?", idGame, BombStatusArmed).
+			Update("status", BombStatusExpired).Error; err != nil {
+			return err
+		}
+
+		var err error
+		result, err = snapshot(tx, idGame, at)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Freeze the result of a game already finished
+func (r *gameResultRepository) Snapshot(idGame uuid.UUID, at time.Time) (*GameResultEntry, error) {
+	var result *GameResultEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = snapshot(tx, idGame, at)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *gameResultRepository) FindByGame(idGame uuid.UUID) (*GameResultEntry, error) {
+	var entry GameResultEntry
+	if err := r.db.First(&entry, idGame).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Build the result of the game from its teams, bombs and score events and store it
+func snapshot(tx *gorm.DB, idGame uuid.UUID, at time.Time) (*GameResultEntry, error) {
+	var game GameEntry
+	if err := tx.Preload("Teams").First(&game, idGame).Error; err != nil {
+		return nil, err
+	}
+
+	result := &GameResultEntry{
+		IDGame:      idGame,
+		FinishedAt:  at,
+		Teams:       rankTeams(game.Teams),
+		BombsByType: map[string]int{},
+	}
+
+	duration, err := runningTime(tx, &game, at)
+	if err != nil {
+		return nil, err
+	}
+	result.Duration = int(duration.Seconds())
+
+	players, err := contributions(tx, &game)
+	if err != nil {
+		return nil, err
+	}
+	result.Players = players
+	for _, player := range players {
+		for typeBomb, count := range player.Bombs {
+			result.BombsByType[typeBomb] += count
+		}
+	}
+
+	if err := tx.Create(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Teams from the best score to the worst, ties share the rank of the first of them
+func rankTeams(teams []TeamEntry) []ResultTeam {
+	ranking := make([]ResultTeam, len(teams))
+	for i, team := range teams {
+		ranking[i] = ResultTeam{IDTeam: team.IDTeam, Name: team.Name, Color: team.Color, Score: team.Score}
+	}
+	slices.SortStableFunc(ranking, func(a, b ResultTeam) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Name, b.Name))
+	})
+
+	for i := range ranking {
+		ranking[i].Rank = i + 1
+		if i > 0 && ranking[i].Score == ranking[i-1].Score {
+			ranking[i].Rank = ranking[i-1].Rank
+		}
+	}
+	return ranking
+}
+
+// Time spent running, from the status changes of the event log.
+// Games without status event count from their starting date.
+func runningTime(tx *gorm.DB, game *GameEntry, at time.Time) (time.Duration, error) {
+	var events []*GameEventEntry
+	if err := tx.Where("id_game = ? AND type = ?", game.IDGame, EventStatusChanged).
+		Order("at, id_event").
+		Find(&events).Error; err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return max(at.Sub(game.StartingDate), 0), nil
+	}
+
+	var total time.Duration
+	var since *time.Time
+	for _, event := range events {
+		if event.Data["to"] == string(GameStatusRunning) && since == nil {
+			since = &event.At
+		} else if event.Data["to"] != string(GameStatusRunning) && since != nil {
+			total += event.At.Sub(*since)
+			since = nil
+		}
+	}
+	if since != nil {
+		total += max(at.Sub(*since), 0)
+	}
+	return total, nil
+}
+
+// Points scored and bombs placed by every player of the game, best player first.
+// Players who left the game are kept along with their team at the time.
+func contributions(tx *gorm.DB, game *GameEntry) ([]ResultPlayer, error) {
+	players := map[uuid.UUID]*ResultPlayer{}
+	player := func(idUser, idTeam uuid.UUID) *ResultPlayer {
+		if players[idUser] == nil {
+			players[idUser] = &ResultPlayer{IDUser: idUser, IDTeam: idTeam, Bombs: map[string]int{}}
+		}
+		return players[idUser]
+	}
+
+	var members []*UserEntry
+	if err := tx.Joins("JOIN team_entries ON team_entries.id_team = user_entries.id_team").
+		Where("team_entries.id_game = ?", game.IDGame).
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		player(member.IDUser, *member.IDTeam)
+	}
+
+	var bombs []struct {
+		IdUser   uuid.UUID
+		IDTeam   uuid.UUID
+		TypeBomb string
+		Count    int
+	}
+	if err := tx.Model(&BombEntry{}).
+		Select("id_user, id_team, type_bomb, COUNT(*) AS count").
+		Where("id_game = ?", game.IDGame).
+		Group("id_user, id_team, type_bomb").
+		Scan(&bombs).Error; err != nil {
+		return nil, err
+	}
+	for _, bomb := range bombs {
+		player(bomb.IdUser, bomb.IDTeam).Bombs[bomb.TypeBomb] += bomb.Count
+	}
+
+	// Manual adjustments are made by admins, they are not part of the play
+	var points []struct {
+		IDUser uuid.UUID
+		IDTeam uuid.UUID
+		Total  int
+	}
+	if err := tx.Model(&ScoreEventEntry{}).
+		Select("id_user, id_team, SUM(points) AS total").
+		Where("id_game = ? AND id_user IS NOT NULL AND reason <> ?", game.IDGame, ScoreReasonManual).
+		Group("id_user, id_team").
+		Scan(&points).Error; err != nil {
+		return nil, err
+	}
+	for _, total := range points {
+		player(total.IDUser, total.IDTeam).Points += total.Total
+	}
+
+	// Names of the players who left the game
+	var missing []uuid.UUID
+	for _, member := range members {
+		players[member.IDUser].UserName = member.UserName
+	}
+	for idUser, p := range players {
+		if p.UserName == "" {
+			missing = append(missing, idUser)
+		}
+	}
+	if len(missing) > 0 {
+		var users []*UserEntry
+		if err := tx.Where("id_user IN ?", missing).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			players[user.IDUser].UserName = user.UserName
+		}
+	}
+
+	res := make([]ResultPlayer, 0, len(players))
+	for _, p := range players {
+		res = append(res, *p)
+	}
+	slices.SortFunc(res, func(a, b ResultPlayer) int {
+		return cmp.Or(cmp.Compare(b.Points, a.Points), cmp.Compare(a.UserName, b.UserName),
+			cmp.Compare(a.IDUser.String(), b.IDUser.String()))
+	})
+	return res, nil
+}
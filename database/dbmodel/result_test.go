@@ -0,0 +1,93 @@
+package dbmodel
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRankTeams(t *testing.T) {
+	team := func(name string, score int) TeamEntry {
+		return TeamEntry{IDTeam: uuid.New(), Name: name, Score: score}
+	}
+	tests := []struct {
+		name  string
+		teams []TeamEntry
+		want  []string // Name and rank of each team, in order
+	}{
+		{"no team", nil, []string{}},
+		{"best score first", []TeamEntry{team("red", 10), team("blue", 30), team("green", 20)}, []string{"blue 1", "green 2", "red 3"}},
+		{"ties share their rank", []TeamEntry{team("red", 10), team("blue", 30), team("green", 30), team("pink", 5)}, []string{"blue 1", "green 1", "red 3", "pink 4"}},
+		{"everyone tied", []TeamEntry{team("b", 0), team("a", 0)}, []string{"a 1", "b 1"}},
+		{"negative scores last", []TeamEntry{team("red", -5), team("blue", 0)}, []string{"blue 1", "red 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, ranked := range rankTeams(tt.teams) {
+				got = append(got, fmt.Sprintf("%s %d", ranked.Name, ranked.Rank))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rankTeams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunningTime(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&GameEventEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	type change struct {
+		minutes int
+		to      GameStatus
+	}
+
+	tests := []struct {
+		name    string
+		changes []change
+		end     time.Time
+		want    time.Duration
+	}{
+		{"no status change counts from the start", nil, at(90), 90 * time.Minute},
+		{"ended before the start", nil, at(-10), 0},
+		{"started then finished", []change{{10, GameStatusRunning}, {70, GameStatusFinished}}, at(80), time.Hour},
+		{"pauses left out", []change{{0, GameStatusRunning}, {20, GameStatusPaused}, {50, GameStatusRunning}, {60, GameStatusFinished}}, at(60), 30 * time.Minute},
+		{"still running", []change{{0, GameStatusScheduled}, {5, GameStatusRunning}}, at(45), 40 * time.Minute},
+		{"never started", []change{{0, GameStatusScheduled}}, at(45), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := &GameEntry{IDGame: uuid.New(), StartingDate: start}
+			for _, c := range tt.changes {
+				event := &GameEventEntry{IDGame: game.IDGame, At: at(c.minutes), Type: EventStatusChanged, Data: map[string]interface{}{"to": c.to}}
+				if err := db.Create(event).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := runningTime(db, game, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("runningTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ScoreReason string
+
+const (
+	ScoreReasonDetonation ScoreReason = "detonation"
+	ScoreReasonTerritory  ScoreReason = "territory"
+	ScoreReasonDefusal    ScoreReason = "defusal"
+	ScoreReasonManual     ScoreReason = "manual"
+	ScoreReasonLegacy     ScoreReason = "legacy" // Score of the team before scores were recorded as events
+)
+
+// Points won or lost by a team. The score of a team is the sum of its score events
+type ScoreEventEntry struct {
+	IDScoreEvent uint        `gorm:"primaryKey;autoIncrement"`
+	IDGame       uuid.UUID   `gorm:"type:uuid;index"`
+	IDTeam       uuid.UUID   `gorm:"type:uuid;index"`
+	IDUser       *uuid.UUID  `gorm:"type:uuid"` // Player who scored or admin who adjusted the score
+	Reason       ScoreReason `gorm:"type:varchar(16)"`
+	Points       int
+	IDBomb       *int
+	Objective    string `gorm:"type:varchar(255)"` // What was scored besides a bomb, or why the score was adjusted
+	At           time.Time
+
+	CreatedAt time.Time
+}
+
+func (e *ScoreEventEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	e.At = e.At.UTC()
+	return
+}
+
+type ScoreRepository interface {
+	Record(entry *ScoreEventEntry) (*ScoreEventEntry, error)
+	FindByGame(idGame uuid.UUID) ([]*ScoreEventEntry, error)
+	Recompute(idGame uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+type scoreRepository struct {
+	db *gorm.DB
+}
+
+func NewScoreRepository(db *gorm.DB) ScoreRepository {
+	return &scoreRepository{db: db}
+}
+
+// Store the score event and add its points to the team
+func (r *scoreRepository) Record(entry *ScoreEventEntry) (*ScoreEventEntry, error) {
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		return addScore(tx, entry)
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *scoreRepository) FindByGame(idGame uuid.UUID) ([]*ScoreEventEntry, error) {
+	var entries []*ScoreEventEntry
+	if err := r.db.Where("id_game = ?", idGame).Order("at, id_score_event").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Rebuild the score of every team of the game from its score events
+func (r *scoreRepository) Recompute(idGame uuid.UUID) (map[uuid.UUID]int, error) {
+	scores := map[uuid.UUID]int{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var teams []*TeamEntry
+		if err := tx.Where("id_game = ?", idGame).Find(&teams).Error; err != nil {
+			return err
+		}
+		for _, team := range teams {
+			scores[team.IDTeam] = 0
+		}
+
+		var totals []struct {
+			IDTeam uuid.UUID
+			Total  int
+		}
+		if len(teams) > 0 {
+			ids := make([]uuid.UUID, len(teams))
+			for i, team := range teams {
+				ids[i] = team.IDTeam
+			}
+			if err := tx.Model(&ScoreEventEntry{}).
+				Select("id_team, SUM(points) AS total").
+				Where("id_team IN ?", ids).
+				Group("id_team").
+				Scan(&totals).Error; err != nil {
+				return err
+			}
+		}
+		for _, total := range totals {
+			scores[total.IDTeam] = total.Total
+		}
+
+		for idTeam, score := range scores {
+			if err := tx.Model(&TeamEntry{}).Where("id_team = ?", idTeam).Update("score", score).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// Store the score event and keep the score of the team in step, within the transaction
+func addScore(tx *gorm.DB, entry *ScoreEventEntry) error {
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+
+	// Increment the score in the database to not lose concurrent changes
+	return tx.Model(&TeamEntry{}).
+		Where("id_team = ?", entry.IDTeam).
+		Update("score", gorm.Expr("score + ?", entry.Points)).Error
+}
@@ -42,8 +42,15 @@ func (r *teamRepository) Create(team *TeamEntry) (*TeamEntry, error) {
 	return team, nil
 }
 
+// Delete the team, its players leave it and their party
 func (r *teamRepository) Delete(uuid uuid.UUID, team *TeamEntry) error {
-	return r.db.Delete(team, uuid).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&UserEntry{}).Where("id_team = ?", uuid).
+			Updates(map[string]interface{}{"id_team": nil, "party": ""}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(team, uuid).Error
+	})
 }
 
 func (r *teamRepository) FindById(uuid uuid.UUID) (*TeamEntry, error) {
@@ -55,8 +62,9 @@ func (r *teamRepository) FindById(uuid uuid.UUID) (*TeamEntry, error) {
 
 }
 
+// Update the team but its score, which only moves with score events
 func (r *teamRepository) Update(team *TeamEntry) (*TeamEntry, error) {
-	if err := r.db.Save(team).Error; err != nil {
+	if err := r.db.Model(team).Select("name", "color", "id_game").Updates(team).Error; err != nil {
 		return nil, err
 	}
 	return team, nil
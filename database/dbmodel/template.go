@@ -0,0 +1,126 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+// Team created along with every game of a template
+type TemplateTeam struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Reusable game setup, dates are kept as offsets resolved when a game is created from it
+type GameTemplateEntry struct {
+	IDTemplate      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	IDOwner         uuid.UUID `gorm:"type:uuid;index"`
+	Name            string    `gorm:"type:varchar(255);"`
+	CenterLatitude  float32
+	CenterLongitude float32
+	Size            float32  // Radius of the play area in meters
+	StartOffset     int      // Seconds between the creation of the game and its start
+	Duration        int      // Seconds between the start and the end of the game
+	Mode            GameMode `gorm:"type:varchar(16);default:classic"`
+
+	Boundary       *geo.MultiPolygon  `gorm:"type:text;serializer:json"`
+	ExclusionZones []geo.MultiPolygon `gorm:"type:text;serializer:json"`
+	ZoneSchedule   *geo.ZoneSchedule  `gorm:"type:text;serializer:json"`
+	Teams          []TemplateTeam     `gorm:"type:text;serializer:json"`
+	Ruleset        rules.Ruleset      `gorm:"type:text;serializer:json"`
+
+	CrudInfo
+}
+
+func (t *GameTemplateEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	t.IDTemplate = uuid.New()
+	if t.Mode == "" {
+		t.Mode = GameModeClassic
+	}
+	return
+}
+
+// Game and teams described by the template, with its dates resolved from now
+func (t *GameTemplateEntry) Instantiate(now time.Time) *GameEntry {
+	return t.InstantiateAt(now.Add(time.Duration(t.StartOffset) * time.Second))
+}
+
+// Game and teams described by the template, starting at the given moment
+func (t *GameTemplateEntry) InstantiateAt(start time.Time) *GameEntry {
+	game := &GameEntry{
+		CenterLatitude:  t.CenterLatitude,
+		CenterLongitude: t.CenterLongitude,
+		Size:            t.Size,
+		StartingDate:    start,
+		EndingDate:      start.Add(time.Duration(t.Duration) * time.Second),
+		Mode:            t.Mode,
+		Boundary:        t.Boundary,
+		ExclusionZones:  t.ExclusionZones,
+		Ruleset:         t.Ruleset,
+	}
+
+	// Each game resolves its own final zone center, keep the template untouched
+	if t.ZoneSchedule != nil {
+		schedule := *t.ZoneSchedule
+		game.ZoneSchedule = &schedule
+	}
+	for _, team := range t.Teams {
+		game.Teams = append(game.Teams, TeamEntry{Name: team.Name, Color: team.Color})
+	}
+	return game
+}
+
+type GameTemplateRepository interface {
+	Create(entry *GameTemplateEntry) (*GameTemplateEntry, error)
+	FindById(id uuid.UUID) (*GameTemplateEntry, error)
+	FindAll() ([]*GameTemplateEntry, error)
+	Update(entry *GameTemplateEntry) (*GameTemplateEntry, error)
+	DeleteById(id uuid.UUID) error
+}
+
+type gameTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewGameTemplateRepository(db *gorm.DB) GameTemplateRepository {
+	return &gameTemplateRepository{db: db}
+}
+
+func (r *gameTemplateRepository) Create(entry *GameTemplateEntry) (*GameTemplateEntry, error) {
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *gameTemplateRepository) FindById(id uuid.UUID) (*GameTemplateEntry, error) {
+	var entry GameTemplateEntry
+	if err := r.db.First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *gameTemplateRepository) FindAll() ([]*GameTemplateEntry, error) {
+	var entries []*GameTemplateEntry
+	if err := r.db.Order("name").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *gameTemplateRepository) Update(entry *GameTemplateEntry) (*GameTemplateEntry, error) {
+	if err := r.db.Save(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *gameTemplateRepository) DeleteById(id uuid.UUID) error {
+	return r.db.Delete(&GameTemplateEntry{}, id).Error
+}
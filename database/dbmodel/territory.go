@@ -0,0 +1,88 @@
+package dbmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"bombparty.com/bombparty-api/pkg/hexgrid"
+)
+
+// Hex cell of a territory game, only captured cells are stored
+type TerritoryCellEntry struct {
+	IDGame     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Q          int       `gorm:"primaryKey;autoIncrement:false"`
+	R          int       `gorm:"primaryKey;autoIncrement:false"`
+	IDTeam     uuid.UUID `gorm:"type:uuid;index"`
+	CapturedAt time.Time
+
+	CrudInfo
+}
+
+type TerritoryRepository interface {
+	Capture(idGame uuid.UUID, cells []hexgrid.Cell, idTeam uuid.UUID, at time.Time) error
+	FindByGame(idGame uuid.UUID) ([]*TerritoryCellEntry, error)
+	CountByTeam(idGame uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+type territoryRepository struct {
+	db *gorm.DB
+}
+
+func NewTerritoryRepository(db *gorm.DB) TerritoryRepository {
+	return &territoryRepository{db: db}
+}
+
+// Give the cells to the team, whoever held them before
+func (r *territoryRepository) Capture(idGame uuid.UUID, cells []hexgrid.Cell, idTeam uuid.UUID, at time.Time) error {
+	if len(cells) == 0 {
+		return nil
+	}
+
+	entries := make([]*TerritoryCellEntry, 0, len(cells))
+	for _, cell := range cells {
+		entries = append(entries, &TerritoryCellEntry{
+			IDGame:     idGame,
+			Q:          cell.Q,
+			R:          cell.R,
+			IDTeam:     idTeam,
+			CapturedAt: at,
+		})
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id_game"}, {Name: "q"}, {Name: "r"}},
+		DoUpdates: clause.AssignmentColumns([]string{"id_team", "captured_at", "updated_at"}),
+	}).Create(&entries).Error
+}
+
+func (r *territoryRepository) FindByGame(idGame uuid.UUID) ([]*TerritoryCellEntry, error) {
+	var entries []*TerritoryCellEntry
+	if err := r.db.Where("id_game = ?", idGame).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Number of cells held by each team of the game
+func (r *territoryRepository) CountByTeam(idGame uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		IDTeam uuid.UUID
+		Cells  int
+	}
+	if err := r.db.Model(&TerritoryCellEntry{}).
+		Select("id_team, COUNT(*) AS cells").
+		Where("id_game = ?", idGame).
+		Group("id_team").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.IDTeam] = row.Cells
+	}
+	return counts, nil
+}
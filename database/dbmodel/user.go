@@ -8,6 +8,13 @@ import (
 	"gorm.io/gorm"
 )
 
+type UserRole string
+
+const (
+	UserRolePlayer UserRole = "player"
+	UserRoleAdmin  UserRole = "admin"
+)
+
 type UserEntry struct {
 	IDUser   uuid.UUID `gorm:"type:uuid; primaryKey"`
 	UserName string    `gorm:"type:varchar(255);unique"`
@@ -15,6 +22,9 @@ type UserEntry struct {
 	Password string
 	IDTeam   *uuid.UUID `gorm:"type:uuid"`
 	Team     *TeamEntry `gorm:"foreignKey:IDTeam;references:IDTeam"`
+	Skill    int        `gorm:"default:1000"`
+	Party    string     `gorm:"type:varchar(32)"` // Players of the same party are kept in the same team
+	Role     UserRole   `gorm:"type:varchar(16);default:player"`
 
 	CrudInfo
 }
@@ -23,6 +33,11 @@ type UserRepository interface {
 	Register(entry *UserEntry) (*UserEntry, error)
 	FindOne(filter, value string) (*UserEntry, error)
 	FindAll() ([]*UserEntry, error)
+	FindByGame(idGame uuid.UUID) ([]*UserEntry, error)
+	JoinTeam(idUser uuid.UUID, idTeam *uuid.UUID, party string) error
+	AssignTeams(assignment map[uuid.UUID]uuid.UUID) error
+	PromoteAdmins(emails []string) error
+	SetSkill(idUser uuid.UUID, skill int) error
 	Login(entry *UserEntry) (*UserEntry, error)
 	Update(entry *UserEntry, email string) (*UserEntry, error)
 	Delete(idUser string) error
@@ -44,6 +59,55 @@ func (r *userRepository) FindAll() ([]*UserEntry, error) {
 	return entries, nil
 }
 
+// Players of the game, through their team
+func (r *userRepository) FindByGame(idGame uuid.UUID) ([]*UserEntry, error) {
+	var entries []*UserEntry
+	if err := r.db.Joins("JOIN team_entries ON team_entries.id_team = user_entries.id_team").
+		Where("team_entries.id_game = ?", idGame).
+		Order("user_entries.user_name").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Move the user into the team, or out of any team when nil
+func (r *userRepository) JoinTeam(idUser uuid.UUID, idTeam *uuid.UUID, party string) error {
+	return r.db.Model(&UserEntry{}).Where("id_user = ?", idUser).
+		Updates(map[string]interface{}{"id_team": idTeam, "party": party}).Error
+}
+
+// Move every user of the assignment into its team at once
+func (r *userRepository) AssignTeams(assignment map[uuid.UUID]uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for idUser, idTeam := range assignment {
+			if err := tx.Model(&UserEntry{}).Where("id_user = ?", idUser).Update("id_team", idTeam).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Give the admin role to the existing users with one of the emails, only done when the server starts
+func (r *userRepository) PromoteAdmins(emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	return r.db.Model(&UserEntry{}).Where("email IN ?", emails).Update("role", UserRoleAdmin).Error
+}
+
+func (r *userRepository) SetSkill(idUser uuid.UUID, skill int) error {
+	result := r.db.Model(&UserEntry{}).Where("id_user = ?", idUser).Update("skill", skill)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *userRepository) FindOne(filter, value string) (*UserEntry, error) {
 	var entries []*UserEntry
 	if err := r.db.Where(filter+" = ?", value).Find(&entries).Error; err != nil {
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	_ "time/tzdata" // Time zones of the recurrences, whatever the host provides
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -15,9 +16,12 @@ import (
 	_ "bombparty.com/bombparty-api/docs" // Import pour initialiser Swagger
 	"bombparty.com/bombparty-api/pkg/authentication"
 	"bombparty.com/bombparty-api/pkg/bomb"
+	"bombparty.com/bombparty-api/pkg/bombtype"
 	"bombparty.com/bombparty-api/pkg/game"
 	"bombparty.com/bombparty-api/pkg/inventory"
 	"bombparty.com/bombparty-api/pkg/team"
+	"bombparty.com/bombparty-api/pkg/template"
+	"bombparty.com/bombparty-api/pkg/territory"
 	"bombparty.com/bombparty-api/pkg/user"
 )
 
@@ -40,6 +44,21 @@ func main() {
 		log.Panicln("Configuration error:", err)
 	}
 
+	// Re-arm the bombs still waiting to explode
+	if err := configuration.Detonator.Start(); err != nil {
+		log.Panicln("Detonation scheduler error:", err)
+	}
+
+	// Territory games earn points for the cells they hold
+	territory.NewAccrual(configuration.GameRepository, configuration.TerritoryRepository, configuration.ScoreRepository,
+		configuration.Events).Start()
+
+	// Create the games of the recurrences ahead of time
+	configuration.Materialiser.Start()
+
+	// Finish the games once their ending date has passed
+	configuration.Finisher.Start()
+
 	// Initialisation des routes
 	router := Routes(configuration)
 
@@ -70,11 +89,14 @@ func Routes(configuration *config.Config) *chi.Mux {
 
 	router.Route("/api/v1", func(r chi.Router) {
 		r.Mount("/bombs", bomb.Routes(configuration))
+		r.Mount("/bomb-types", bombtype.Routes(configuration))
 		r.Mount("/auth", authentication.Routes(configuration))
 		r.Mount("/users", user.Routes(configuration))
 		r.Mount("/inventory", inventory.Routes(configuration))
 		r.Mount("/games", game.Routes(configuration))
 		r.Mount("/teams", team.Routes(configuration))
+		r.Mount("/templates", template.Routes(configuration))
+		r.Mount("/recurrences", game.RecurrenceRoutes(configuration))
 	})
 
 	return router
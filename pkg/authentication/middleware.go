@@ -3,6 +3,10 @@ package authentication
 import (
 	"context"
 	"net/http"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
 )
 
 func AuthMiddleware(secret string) func(http.Handler) http.Handler {
@@ -26,7 +30,39 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 	}
 }
 
+// Only let the admins through, must come after AuthMiddleware
+func AdminMiddleware(users dbmodel.UserRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := CurrentUser(r, users)
+			if err != nil {
+				http.Error(w, "User not found", http.StatusUnauthorized)
+				return
+			}
+			if user.Role != dbmodel.UserRoleAdmin {
+				http.Error(w, "Admin role required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func GetUserFromContext(ctx context.Context) string {
 	email, _ := ctx.Value("email").(string)
 	return email
 }
+
+// Fetch the user owning the token of the request
+func CurrentUser(r *http.Request, users dbmodel.UserRepository) (*dbmodel.UserEntry, error) {
+	return users.FindOne("email", GetUserFromContext(r.Context()))
+}
+
+// Id of the user owning the token of the request, nil when the user is unknown
+func CurrentUserId(r *http.Request, users dbmodel.UserRepository) *uuid.UUID {
+	user, err := CurrentUser(r, users)
+	if err != nil {
+		return nil
+	}
+	return &user.IDUser
+}
@@ -0,0 +1,113 @@
+// Package balance spreads the players of a game over its teams.
+//
+// Players of the same party always end up in the same team, the strategy of
+// the game decides where each group goes.
+package balance
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type Player struct {
+	ID    uuid.UUID
+	Team  uuid.UUID // Nil while the player has no team
+	Skill int
+	Party string // Empty for players joining alone
+}
+
+// Players placed together
+type Group []Player
+
+func (g Group) Skill() int {
+	skill := 0
+	for _, player := range g {
+		skill += player.Skill
+	}
+	return skill
+}
+
+type Team struct {
+	ID      uuid.UUID
+	Players []Player
+}
+
+func (t Team) Skill() int {
+	return Group(t.Players).Skill()
+}
+
+type Strategy interface {
+	// Sort the groups in the order they are placed during a rebalance
+	Order(groups []Group)
+	// Team receiving the group
+	Pick(teams []Team, group Group) uuid.UUID
+}
+
+// Team of the game for a newcomer, the team of its party if one of its members already joined
+func Assign(strategy Strategy, teams []uuid.UUID, players []Player, newcomer Player) uuid.UUID {
+	if newcomer.Party != "" {
+		for _, player := range players {
+			if player.ID != newcomer.ID && player.Party == newcomer.Party && slices.Contains(teams, player.Team) {
+				return player.Team
+			}
+		}
+	}
+
+	others := slices.DeleteFunc(slices.Clone(players), func(p Player) bool { return p.ID == newcomer.ID })
+	return strategy.Pick(fill(teams, others), Group{newcomer})
+}
+
+// Team of every player once the game is reshuffled from empty teams
+func Rebalance(strategy Strategy, teams []uuid.UUID, players []Player) map[uuid.UUID]uuid.UUID {
+	groups := groupByParty(players)
+	strategy.Order(groups)
+
+	filled := fill(teams, nil)
+	assignment := map[uuid.UUID]uuid.UUID{}
+	for _, group := range groups {
+		id := strategy.Pick(filled, group)
+		for i := range filled {
+			if filled[i].ID == id {
+				filled[i].Players = append(filled[i].Players, group...)
+			}
+		}
+		for _, player := range group {
+			assignment[player.ID] = id
+		}
+	}
+	return assignment
+}
+
+// Teams holding the players already in them, in the given order
+func fill(teams []uuid.UUID, players []Player) []Team {
+	filled := make([]Team, len(teams))
+	for i, id := range teams {
+		filled[i].ID = id
+		for _, player := range players {
+			if player.Team == id {
+				filled[i].Players = append(filled[i].Players, player)
+			}
+		}
+	}
+	return filled
+}
+
+// Parties as groups and solo players as groups of one, in the order of the players
+func groupByParty(players []Player) []Group {
+	var groups []Group
+	parties := map[string]int{}
+	for _, player := range players {
+		if player.Party == "" {
+			groups = append(groups, Group{player})
+			continue
+		}
+		if i, ok := parties[player.Party]; ok {
+			groups[i] = append(groups[i], player)
+			continue
+		}
+		parties[player.Party] = len(groups)
+		groups = append(groups, Group{player})
+	}
+	return groups
+}
@@ -0,0 +1,172 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	red  = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	blue = uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+)
+
+func player(n int, team uuid.UUID, skill int, party string) Player {
+	return Player{ID: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0001-%012d", n)), Team: team, Skill: skill, Party: party}
+}
+
+func TestAssign(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		players  []Player
+		newcomer Player
+		want     uuid.UUID
+	}{
+		{
+			name:     "empty teams take the first one",
+			strategy: "size",
+			newcomer: player(1, uuid.Nil, 1000, ""),
+			want:     red,
+		},
+		{
+			name:     "smallest team",
+			strategy: "size",
+			players:  []Player{player(1, red, 1000, ""), player(2, red, 1000, ""), player(3, blue, 1000, "")},
+			newcomer: player(4, uuid.Nil, 1000, ""),
+			want:     blue,
+		},
+		{
+			name:     "party mate already in a team",
+			strategy: "size",
+			players:  []Player{player(1, red, 1000, "duo"), player(2, red, 1000, ""), player(3, blue, 1000, "")},
+			newcomer: player(4, uuid.Nil, 1000, "duo"),
+			want:     red,
+		},
+		{
+			name:     "party mate without team",
+			strategy: "size",
+			players:  []Player{player(1, uuid.Nil, 1000, "duo"), player(2, red, 1000, "")},
+			newcomer: player(3, uuid.Nil, 1000, "duo"),
+			want:     blue,
+		},
+		{
+			name:     "newcomer already counted in a team",
+			strategy: "size",
+			players:  []Player{player(1, red, 1000, ""), player(2, blue, 1000, "")},
+			newcomer: player(1, red, 1000, ""),
+			want:     red,
+		},
+		{
+			name:     "equal sizes go to the weakest team",
+			strategy: "skill",
+			players:  []Player{player(1, red, 2000, ""), player(2, blue, 800, "")},
+			newcomer: player(3, uuid.Nil, 1500, ""),
+			want:     blue,
+		},
+		{
+			name:     "size before skill",
+			strategy: "skill",
+			players:  []Player{player(1, red, 500, ""), player(2, red, 500, ""), player(3, blue, 3000, "")},
+			newcomer: player(4, uuid.Nil, 1000, ""),
+			want:     blue,
+		},
+		{
+			name:     "unknown strategy falls back to the default",
+			strategy: "unknown",
+			players:  []Player{player(1, red, 1000, "")},
+			newcomer: player(2, uuid.Nil, 1000, ""),
+			want:     blue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Assign(StrategyOf(tt.strategy), []uuid.UUID{red, blue}, tt.players, tt.newcomer)
+			if got != tt.want {
+				t.Errorf("Assign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  string
+		players   []Player
+		wantSizes map[uuid.UUID]int
+		wantSkill map[uuid.UUID]int // Checked when set
+	}{
+		{
+			name:      "even split",
+			strategy:  "size",
+			players:   []Player{player(1, red, 1000, ""), player(2, red, 1000, ""), player(3, red, 1000, ""), player(4, red, 1000, "")},
+			wantSizes: map[uuid.UUID]int{red: 2, blue: 2},
+		},
+		{
+			name:     "party kept together",
+			strategy: "size",
+			players: []Player{player(1, blue, 1000, ""), player(2, red, 1000, "trio"), player(3, blue, 1000, "trio"),
+				player(4, red, 1000, "trio"), player(5, red, 1000, "")},
+			wantSizes: map[uuid.UUID]int{red: 3, blue: 2},
+		},
+		{
+			name:     "skills evened out",
+			strategy: "skill",
+			players: []Player{player(1, red, 3000, ""), player(2, red, 2500, ""), player(3, blue, 1000, ""),
+				player(4, blue, 500, "")},
+			wantSizes: map[uuid.UUID]int{red: 2, blue: 2},
+			wantSkill: map[uuid.UUID]int{red: 3500, blue: 3500},
+		},
+		{
+			name:      "no player",
+			strategy:  "skill",
+			wantSizes: map[uuid.UUID]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := Rebalance(StrategyOf(tt.strategy), []uuid.UUID{red, blue}, tt.players)
+			if len(assignment) != len(tt.players) {
+				t.Fatalf("Rebalance() placed %d players, want %d", len(assignment), len(tt.players))
+			}
+
+			sizes, skills, parties := map[uuid.UUID]int{}, map[uuid.UUID]int{}, map[string]uuid.UUID{}
+			for _, p := range tt.players {
+				team := assignment[p.ID]
+				sizes[team]++
+				skills[team] += p.Skill
+				if p.Party == "" {
+					continue
+				}
+				if previous, ok := parties[p.Party]; ok && previous != team {
+					t.Errorf("party %s split between %s and %s", p.Party, previous, team)
+				}
+				parties[p.Party] = team
+			}
+			for team, want := range tt.wantSizes {
+				if sizes[team] != want {
+					t.Errorf("team %s has %d players, want %d", team, sizes[team], want)
+				}
+			}
+			for team, want := range tt.wantSkill {
+				if skills[team] != want {
+					t.Errorf("team %s has a skill of %d, want %d", team, skills[team], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{{"size", true}, {"skill", true}, {Default, true}, {"random", false}, {"", false}}
+	for _, tt := range tests {
+		if got := Exists(tt.name); got != tt.want {
+			t.Errorf("Exists(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package balance
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Strategy used by the games that did not pick one
+const Default = "size"
+
+var strategies = map[string]Strategy{
+	"size":  sizeStrategy{},
+	"skill": skillStrategy{},
+}
+
+// Make a strategy available to the games under the name
+func Register(name string, strategy Strategy) {
+	strategies[name] = strategy
+}
+
+func Exists(name string) bool {
+	_, ok := strategies[name]
+	return ok
+}
+
+// Strategy registered under the name, the default one if unknown
+func StrategyOf(name string) Strategy {
+	if strategy, ok := strategies[name]; ok {
+		return strategy
+	}
+	return strategies[Default]
+}
+
+// Evens out the number of players, placing the biggest parties first
+type sizeStrategy struct{}
+
+func (sizeStrategy) Order(groups []Group) {
+	slices.SortStableFunc(groups, func(a, b Group) int {
+		return cmp.Compare(len(b), len(a))
+	})
+}
+
+func (sizeStrategy) Pick(teams []Team, group Group) uuid.UUID {
+	best := teams[0]
+	for _, team := range teams[1:] {
+		if len(team.Players) < len(best.Players) {
+			best = team
+		}
+	}
+	return best.ID
+}
+
+// Evens out the number of players then the total skill of the teams,
+// placing the biggest then strongest groups first
+type skillStrategy struct{}
+
+func (skillStrategy) Order(groups []Group) {
+	slices.SortStableFunc(groups, func(a, b Group) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(b.Skill(), a.Skill()))
+	})
+}
+
+func (skillStrategy) Pick(teams []Team, group Group) uuid.UUID {
+	best := teams[0]
+	for _, team := range teams[1:] {
+		if c := cmp.Or(cmp.Compare(len(team.Players), len(best.Players)), cmp.Compare(team.Skill(), best.Skill())); c < 0 {
+			best = team
+		}
+	}
+	return best.ID
+}
@@ -1,15 +1,24 @@
 package bomb
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"bombparty.com/bombparty-api/config"
 	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/detonation"
+	"bombparty.com/bombparty-api/pkg/fog"
+	"bombparty.com/bombparty-api/pkg/geo"
 	"bombparty.com/bombparty-api/pkg/model"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type BombConfig struct {
@@ -22,7 +31,7 @@ func New(configuration *config.Config) *BombConfig {
 
 // CreateBomb godoc
 // @Summary      Create a new bomb
-// @Description  Create a new bomb with the provided data
+// @Description  Place a new bomb with the provided data, taking it from the inventory of the user
 // @Tags         Bombs
 // @Security     BearerAuth
 // @Accept       json
@@ -30,6 +39,10 @@ func New(configuration *config.Config) *BombConfig {
 // @Param        bomb body     model.BombRequest true "Bomb data"
 // @Success      201  {object} model.BombResponse
 // @Failure      400  {object} map[string]string
+// @Failure      403  {object} map[string]string
+// @Failure      409  {object} map[string]string
+// @Failure      422  {object} map[string]string
+// @Failure      429  {object} model.ThrottleResponse
 // @Failure      500  {object} map[string]string
 // @Router       /api/v1/bombs [post]
 func (c *BombConfig) CreateBomb(w http.ResponseWriter, r *http.Request) {
@@ -40,27 +53,93 @@ func (c *BombConfig) CreateBomb(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, err := authentication.CurrentUser(r, c.UserRepository)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "User not found"})
+		return
+	}
+	if req.IdUser != uuid.Nil && req.IdUser != user.IDUser {
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "Bombs can only be placed by their owner"})
+		return
+	}
+
+	// The team of the user must play in the requested game
+	membership, err := c.GameRepository.FindByUserId(user.IDUser)
+	if err != nil || membership.IDGame != req.IDGame {
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "User is not part of this game"})
+		return
+	}
+
+	game, ok := c.findBombGame(w, r, req.IDGame)
+	if !ok {
+		return
+	}
+
+	if !checkInPlayArea(w, r, game, req.Lat, req.Long) {
+		return
+	}
+	if !checkBombAllowed(w, r, game, req.TypeBomb) {
+		return
+	}
+	bombType, ok := c.findBombType(w, r, req.TypeBomb)
+	if !ok {
+		return
+	}
+
+	// The fuse starts burning as soon as the bomb is placed, mines only expire once it has burnt
+	fuse := detonation.BlastOf(bombType).Fuse
+	if req.Fuse != nil {
+		fuse = time.Duration(*req.Fuse) * time.Second
+	}
+	placedAt := time.Now()
+
 	bombEntry := dbmodel.BombEntry{
-		Lat:      req.Lat,
-		Long:     req.Long,
-		TypeBomb: req.TypeBomb,
-		IdUser:   req.IdUser,
+		Lat:        req.Lat,
+		Long:       req.Long,
+		TypeBomb:   req.TypeBomb,
+		IdUser:     user.IDUser,
+		IDGame:     game.IDGame,
+		IDTeam:     *user.IDTeam,
+		PlacedAt:   placedAt,
+		Fuse:       int(fuse.Seconds()),
+		DetonateAt: placedAt.Add(fuse),
+		Status:     dbmodel.BombStatusArmed,
+
+		Behaviour:     bombType.Behaviour,
+		TriggerRadius: bombType.TriggerRadius,
 	}
 
-	bomb, err := c.BombRepository.Create(&bombEntry)
-	if err != nil {
+	bomb, err := c.BombRepository.Create(&bombEntry, &game.Ruleset)
+	var throttle *dbmodel.ThrottleError
+	switch {
+	case errors.Is(err, dbmodel.ErrNoBombLeft):
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "No " + req.TypeBomb + " bomb left in the inventory"})
+		return
+	case errors.As(err, &throttle):
+		retryAfter := int(math.Ceil(time.Until(throttle.RetryAt).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		render.JSON(w, r, model.ThrottleResponse{
+			Error:      "Bomb cannot be placed yet",
+			Reason:     string(throttle.Reason),
+			RetryAt:    throttle.RetryAt,
+			RetryAfter: retryAfter,
+		})
+		return
+	case err != nil:
 		w.WriteHeader(http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Error creating bomb"})
 		return
 	}
 
-	res := &model.BombResponse{
-		BombId:   bomb.BombID,
-		Lat:      bombEntry.Lat,
-		Long:     bombEntry.Long,
-		TypeBomb: bombEntry.TypeBomb,
-		IdUser:   bombEntry.IdUser,
-	}
+	c.Detonator.Arm(bomb)
+	c.Events.BombPlaced(bomb, user.IDUser)
+
+	res := convertToResponse(bomb)
 	w.WriteHeader(http.StatusCreated)
 	render.JSON(w, r, res)
 }
@@ -79,103 +158,151 @@ func (c *BombConfig) CreateBomb(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/bombs/{id} [get]
 func (c *BombConfig) GetBomb(w http.ResponseWriter, r *http.Request) {
-	strId := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(strId)
-	if err != nil || id < 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{"error": "Invalid id parameter"})
+	bomb, ok := c.findVisibleBomb(w, r)
+	if !ok {
 		return
 	}
 
-	bomb, err := c.BombRepository.FindById(id)
+	res := convertToResponse(bomb)
+	render.JSON(w, r, res)
+}
+
+// GetAllBombs godoc
+// @Summary List the bombs of my game
+// @Description Get the bombs of the game the authenticated user plays in, hiding the armed bombs of the other teams too far from them
+// @Tags Bombs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.BombResponse
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/bombs [get]
+func (c *BombConfig) GetAllBombs(w http.ResponseWriter, r *http.Request) {
+	game, ok := c.findCurrentGame(w, r)
+	if !ok {
+		return
+	}
+
+	// Users outside of any game see no bomb
+	if game == nil {
+		render.JSON(w, r, []model.BombResponse{})
+		return
+	}
+
+	viewer, ok := c.viewer(w, r)
+	if !ok {
+		return
+	}
+
+	bombs, err := c.BombRepository.FindAllByGameId(game.IDGame)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		render.JSON(w, r, map[string]string{"error": "Bomb not found"})
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching bombs"})
 		return
 	}
+	bombs = viewer.Filter(bombs)
 
-	res := &model.BombResponse{
-		Lat:      bomb.Lat,
-		Long:     bomb.Long,
-		TypeBomb: bomb.TypeBomb,
-		IdUser:   bomb.IdUser,
+	responses := make([]model.BombResponse, len(bombs))
+	for i, bomb := range bombs {
+		responses[i] = *convertToResponse(bomb)
 	}
-	render.JSON(w, r, res)
+	render.JSON(w, r, responses)
 }
 
-// GetAllBombs godoc
-// @Summary List all bombs
-// @Description Get all bombs
+// GetGameBombs godoc
+// @Summary List bombs of a game
+// @Description Get the bombs placed in a game, hiding the armed bombs of the other teams too far from the authenticated user
 // @Tags Bombs
 // @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param id path string true "Game ID"
 // @Success 200 {array} model.BombResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /api/v1/bombs [get]
-func (c *BombConfig) GetAllBombs(w http.ResponseWriter, r *http.Request) {
-	bombs, err := c.BombRepository.FindAll()
+// @Router /api/v1/games/{id}/bombs [get]
+func (c *BombConfig) GetGameBombs(w http.ResponseWriter, r *http.Request) {
+	idGame, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid id parameter"})
+		return
+	}
+
+	user, err := authentication.CurrentUser(r, c.UserRepository)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "User not found"})
+		return
+	}
+
+	// Private games don't exist for the users outside of them
+	game, err := c.GameRepository.FindById(idGame)
+	if err != nil || !game.VisibleTo(user) {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Game not found"})
+		return
+	}
+
+	bombs, err := c.BombRepository.FindAllByGameId(idGame)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Error fetching bombs"})
 		return
 	}
+	bombs = fog.New(user, c.GameRepository, c.PositionRepository).Filter(bombs)
 
 	responses := make([]model.BombResponse, len(bombs))
 	for i, bomb := range bombs {
-		responses[i] = model.BombResponse{
-			Lat:      bomb.Lat,
-			Long:     bomb.Long,
-			TypeBomb: bomb.TypeBomb,
-			IdUser:   bomb.IdUser,
-		}
+		responses[i] = *convertToResponse(bomb)
 	}
 	render.JSON(w, r, responses)
 }
 
 // GetBombsByUserId godoc
 // @Summary List bombs by user
-// @Description Get all bombs created by a specific user
+// @Description Get the bombs placed by a specific user, hiding the armed ones the authenticated user is too far from
 // @Tags Bombs
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param userId path int true "User ID"
+// @Param userId path string true "User ID"
 // @Success 200 {array} model.BombResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/bombs/user/{userId} [get]
 func (c *BombConfig) GetBombsByUserId(w http.ResponseWriter, r *http.Request) {
-	strId := chi.URLParam(r, "userId")
-	userId, err := strconv.Atoi(strId)
-	if err != nil || userId < 0 {
+	userId, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		render.JSON(w, r, map[string]string{"error": "Invalid user id parameter"})
 		return
 	}
 
+	viewer, ok := c.viewer(w, r)
+	if !ok {
+		return
+	}
+
 	bombs, err := c.BombRepository.FindAllByUserId(userId)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Error fetching bombs"})
 		return
 	}
+	bombs = viewer.Filter(bombs)
 
 	responses := make([]model.BombResponse, len(bombs))
 	for i, bomb := range bombs {
-		responses[i] = model.BombResponse{
-			Lat:      bomb.Lat,
-			Long:     bomb.Long,
-			TypeBomb: bomb.TypeBomb,
-			IdUser:   bomb.IdUser,
-		}
+		responses[i] = *convertToResponse(bomb)
 	}
 	render.JSON(w, r, responses)
 }
 
 // UpdateBomb godoc
 // @Summary Update a bomb
-// @Description Update an existing bomb
+// @Description Move an armed bomb, only its owner, the host of its game and the admins can
 // @Tags Bombs
 // @Security BearerAuth
 // @Accept json
@@ -184,22 +311,28 @@ func (c *BombConfig) GetBombsByUserId(w http.ResponseWriter, r *http.Request) {
 // @Param bomb body model.BombUpdateRequest true "Bomb update data"
 // @Success 200 {object} model.BombResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/bombs/{id} [put]
 func (c *BombConfig) UpdateBomb(w http.ResponseWriter, r *http.Request) {
-	strId := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(strId)
-	if err != nil || id < 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{"error": "Invalid id parameter"})
+	bomb, ok := c.findVisibleBomb(w, r)
+	if !ok {
 		return
 	}
 
-	bomb, err := c.BombRepository.FindById(id)
-	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		render.JSON(w, r, map[string]string{"error": "Bomb not found"})
+	if bomb.Status != dbmodel.BombStatusArmed {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Bomb is not armed anymore"})
+		return
+	}
+
+	game, ok := c.findBombGame(w, r, bomb.IDGame)
+	if !ok {
+		return
+	}
+	if !c.checkBombManager(w, r, bomb, game) {
 		return
 	}
 
@@ -210,35 +343,48 @@ func (c *BombConfig) UpdateBomb(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Locked bombs stay where they were placed
+	if game.Ruleset.LockBombs && (req.Lat != nil && *req.Lat != bomb.Lat || req.Long != nil && *req.Long != bomb.Long) {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Bombs cannot be moved in this game"})
+		return
+	}
+
+	// The bomb was paid for and throttled as its type, another one has to be placed instead
+	if req.TypeBomb != nil && *req.TypeBomb != bomb.TypeBomb {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "The type of a placed bomb cannot change"})
+		return
+	}
+
+	previous := *bomb
 	if req.Lat != nil {
 		bomb.Lat = *req.Lat
 	}
 	if req.Long != nil {
 		bomb.Long = *req.Long
 	}
-	if req.TypeBomb != nil {
-		bomb.TypeBomb = *req.TypeBomb
+
+	if !checkInPlayArea(w, r, game, bomb.Lat, bomb.Long) {
+		return
 	}
 
-	bomb, err = c.BombRepository.Update(bomb)
+	bomb, err := c.BombRepository.Update(bomb)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Error updating bomb"})
 		return
 	}
+	c.Events.BombMoved(bomb, previous, authentication.CurrentUserId(r, c.UserRepository))
 
-	res := &model.BombResponse{
-		Lat:      bomb.Lat,
-		Long:     bomb.Long,
-		TypeBomb: bomb.TypeBomb,
-		IdUser:   bomb.IdUser,
-	}
+	res := convertToResponse(bomb)
 	render.JSON(w, r, res)
 }
 
 // DeleteBomb godoc
 // @Summary Delete a bomb
-// @Description Delete a bomb by ID
+// @Description Delete a bomb by ID, an armed bomb goes back to the inventory of its owner.
+// @Description Only its owner, the host of its game and the admins can
 // @Tags Bombs
 // @Security BearerAuth
 // @Accept json
@@ -246,10 +392,80 @@ func (c *BombConfig) UpdateBomb(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Bomb ID"
 // @Success 204
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/bombs/{id} [delete]
 func (c *BombConfig) DeleteBomb(w http.ResponseWriter, r *http.Request) {
+	bomb, ok := c.findVisibleBomb(w, r)
+	if !ok {
+		return
+	}
+
+	game, ok := c.findBombGame(w, r, bomb.IDGame)
+	if !ok {
+		return
+	}
+	if !c.checkBombManager(w, r, bomb, game) {
+		return
+	}
+
+	err := c.BombRepository.Delete(bomb.BombID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error deleting bomb"})
+		return
+	}
+	c.Events.BombRemoved(bomb, authentication.CurrentUserId(r, c.UserRepository))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDetonation godoc
+// @Summary Get the detonation of a bomb
+// @Description Get the blast radius, damage and points of a detonated bomb
+// @Tags Bombs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Bomb ID"
+// @Success 200 {object} model.DetonationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/bombs/{id}/detonation [get]
+func (c *BombConfig) GetDetonation(w http.ResponseWriter, r *http.Request) {
+	strId := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(strId)
+	if err != nil || id < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid id parameter"})
+		return
+	}
+
+	entry, err := c.DetonationRepository.FindByBomb(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Bomb has not detonated"})
+		return
+	}
+
+	render.JSON(w, r, detonationResponse(entry))
+}
+
+// GetChain godoc
+// @Summary Get the chain reaction of a bomb
+// @Description Get every detonation of the chain reaction the bomb exploded in, in the order they happened
+// @Tags Bombs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Bomb ID"
+// @Success 200 {object} model.ChainResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/bombs/{id}/chain [get]
+func (c *BombConfig) GetChain(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(strId)
 	if err != nil || id < 0 {
@@ -258,12 +474,202 @@ func (c *BombConfig) DeleteBomb(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = c.BombRepository.Delete(id)
+	entry, err := c.DetonationRepository.FindByBomb(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Bomb has not detonated"})
+		return
+	}
+
+	entries, err := c.DetonationRepository.FindChain(entry.IDChain)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		render.JSON(w, r, map[string]string{"error": "Error deleting bomb"})
+		render.JSON(w, r, map[string]string{"error": "Error retrieving chain reaction"})
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	res := &model.ChainResponse{IDChain: entry.IDChain, Detonations: []*model.DetonationResponse{}}
+	for _, detonation := range entries {
+		res.Points += detonation.Points
+		res.Detonations = append(res.Detonations, detonationResponse(detonation))
+	}
+	render.JSON(w, r, res)
+}
+
+func detonationResponse(entry *dbmodel.DetonationEntry) *model.DetonationResponse {
+	return &model.DetonationResponse{
+		BombId:      entry.IDBomb,
+		IDGame:      entry.IDGame,
+		IDTeam:      entry.IDTeam,
+		DetonatedAt: entry.DetonatedAt,
+		Lat:         entry.Lat,
+		Long:        entry.Long,
+		Radius:      entry.Radius,
+		Damage:      entry.Damage,
+		Points:      entry.Points,
+		Hits:        entry.Hits,
+		IDChain:     entry.IDChain,
+		TriggeredBy: entry.IDTrigger,
+		Hop:         entry.Hop,
+		TrippedBy:   entry.TrippedBy,
+	}
+}
+
+// Bomb of the id in the URL, the bombs hidden from the authenticated user by the fog of war are not found
+func (c *BombConfig) findVisibleBomb(w http.ResponseWriter, r *http.Request) (*dbmodel.BombEntry, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid id parameter"})
+		return nil, false
+	}
+
+	bomb, err := c.BombRepository.FindById(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Bomb not found"})
+		return nil, false
+	}
+
+	viewer, ok := c.viewer(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !viewer.Sees(bomb) {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Bomb not found"})
+		return nil, false
+	}
+
+	return bomb, true
+}
+
+// Only the owner of the bomb, the host of its game and the admins can move or remove it
+func (c *BombConfig) checkBombManager(w http.ResponseWriter, r *http.Request, bomb *dbmodel.BombEntry, game *dbmodel.GameEntry) bool {
+	user, err := authentication.CurrentUser(r, c.UserRepository)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "User not found"})
+		return false
+	}
+	if bomb.IdUser != user.IDUser && !game.ManagedBy(user) {
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "Only the owner of the bomb or the host of the game can change it"})
+		return false
+	}
+	return true
+}
+
+// What the authenticated user sees of the bombs
+func (c *BombConfig) viewer(w http.ResponseWriter, r *http.Request) (*fog.Viewer, bool) {
+	user, err := authentication.CurrentUser(r, c.UserRepository)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "User not found"})
+		return nil, false
+	}
+	return fog.New(user, c.GameRepository, c.PositionRepository), true
+}
+
+// Fetch the game the authenticated user plays in, nil if the user is in no game
+func (c *BombConfig) findCurrentGame(w http.ResponseWriter, r *http.Request) (*dbmodel.GameEntry, bool) {
+	user, err := authentication.CurrentUser(r, c.UserRepository)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "User not found"})
+		return nil, false
+	}
+
+	game, err := c.GameRepository.FindByUserId(user.IDUser)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, true
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching game"})
+		return nil, false
+	}
+	return game, true
+}
+
+// Fetch the game of the bomb and check it is in a status accepting bomb changes
+func (c *BombConfig) findBombGame(w http.ResponseWriter, r *http.Request, idGame uuid.UUID) (*dbmodel.GameEntry, bool) {
+	game, err := c.GameRepository.FindById(idGame)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Game not found"})
+		return nil, false
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching game"})
+		return nil, false
+	}
+
+	if !game.AcceptsBombs() {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Game is " + string(game.Status) + ", bombs cannot be changed"})
+		return nil, false
+	}
+
+	return game, true
+}
+
+// Registered bomb type players can still place
+func (c *BombConfig) findBombType(w http.ResponseWriter, r *http.Request, typeBomb string) (*dbmodel.BombTypeEntry, bool) {
+	bombType, err := c.BombTypeRepository.FindByType(typeBomb)
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && !bombType.Enabled {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		render.JSON(w, r, map[string]string{"error": "Unknown bomb type " + typeBomb})
+		return nil, false
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching bomb type"})
+		return nil, false
+	}
+
+	return bombType, true
+}
+
+// Check that the coordinates fall inside the play area of the game, as it is right now
+func checkInPlayArea(w http.ResponseWriter, r *http.Request, game *dbmodel.GameEntry, lat, long float32) bool {
+	if err := game.PlayAreaAt(time.Now()).Check(geo.NewPoint(lat, long)); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		render.JSON(w, r, map[string]string{"error": "Bomb is " + err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func checkBombAllowed(w http.ResponseWriter, r *http.Request, game *dbmodel.GameEntry, typeBomb string) bool {
+	if !game.Ruleset.AllowsBomb(typeBomb) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		render.JSON(w, r, map[string]string{"error": "Bomb type " + typeBomb + " is not allowed in this game"})
+		return false
+	}
+
+	return true
+}
+
+func convertToResponse(bomb *dbmodel.BombEntry) *model.BombResponse {
+	return &model.BombResponse{
+		BombId:     bomb.BombID,
+		Lat:        bomb.Lat,
+		Long:       bomb.Long,
+		TypeBomb:   bomb.TypeBomb,
+		IdUser:     bomb.IdUser,
+		IDGame:     bomb.IDGame,
+		IDTeam:     bomb.IDTeam,
+		PlacedAt:   bomb.PlacedAt,
+		Fuse:       bomb.Fuse,
+		DetonateAt: bomb.DetonateAt,
+		Status:     string(bomb.Status),
+		DefusedBy:  bomb.DefusedBy,
+		DefusedAt:  bomb.DefusedAt,
+
+		Behaviour:     string(bomb.Behaviour),
+		TriggerRadius: bomb.TriggerRadius,
+	}
 }
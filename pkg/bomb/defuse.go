@@ -0,0 +1,208 @@
+package bomb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+// Positions older than this don't tell where the player is anymore
+const positionMaxAge = 2 * time.Minute
+
+// StartDefuse godoc
+// @Summary Start defusing a bomb
+// @Description Start defusing an armed bomb of an opposing team. The last reported position of the player must be close to the bomb,
+// @Description the defusal is confirmed once its ready_at has passed
+// @Tags Bombs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Bomb ID"
+// @Success 201 {object} model.DefusalResponse
+// @Success 200 {object} model.DefusalResponse "Defusal already started"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/bombs/{id}/defuse [post]
+func (c *BombConfig) StartDefuse(w http.ResponseWriter, r *http.Request) {
+	bomb, game, user, ok := c.checkDefuser(w, r)
+	if !ok {
+		return
+	}
+
+	// Starting again keeps the time already spent
+	pending, err := c.DefusalRepository.FindPending(bomb.BombID, user.IDUser)
+	if err == nil {
+		render.JSON(w, r, convertToDefusalResponse(pending, 0))
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching defusal"})
+		return
+	}
+
+	now := time.Now()
+	defusal, err := c.DefusalRepository.Start(&dbmodel.DefusalEntry{
+		IDBomb:    bomb.BombID,
+		IDUser:    user.IDUser,
+		IDTeam:    *user.IDTeam,
+		StartedAt: now,
+		ReadyAt:   now.Add(game.Ruleset.DefuseDuration()),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error starting defusal"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	render.JSON(w, r, convertToDefusalResponse(defusal, 0))
+}
+
+// ConfirmDefuse godoc
+// @Summary Confirm the defusal of a bomb
+// @Description Defuse the bomb once the defusal time has passed, the player must still be close to it.
+// @Description The team of the player scores the defuse points of the game
+// @Tags Bombs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Bomb ID"
+// @Success 200 {object} model.DefusalResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/bombs/{id}/defuse/confirm [post]
+func (c *BombConfig) ConfirmDefuse(w http.ResponseWriter, r *http.Request) {
+	bomb, game, user, ok := c.checkDefuser(w, r)
+	if !ok {
+		return
+	}
+
+	defusal, err := c.DefusalRepository.FindPending(bomb.BombID, user.IDUser)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "No defusal started on this bomb"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching defusal"})
+		return
+	}
+
+	now := time.Now()
+	if now.Before(defusal.ReadyAt) {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{
+			"error":    "Defusal is not done yet",
+			"ready_at": defusal.ReadyAt.Format(time.RFC3339Nano),
+		})
+		return
+	}
+
+	score := &dbmodel.ScoreEventEntry{
+		IDGame: game.IDGame,
+		IDTeam: defusal.IDTeam,
+		IDUser: &defusal.IDUser,
+		Reason: dbmodel.ScoreReasonDefusal,
+		Points: game.Ruleset.DefuseScore(),
+		IDBomb: &bomb.BombID,
+		At:     now,
+	}
+	defusal, err = c.DefusalRepository.Confirm(defusal, now, score)
+	if errors.Is(err, dbmodel.ErrBombNotArmed) {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Bomb is not armed anymore"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error defusing bomb"})
+		return
+	}
+
+	c.Events.BombDefused(bomb, defusal)
+	c.Events.ScoreChanged(score)
+
+	render.JSON(w, r, convertToDefusalResponse(defusal, score.Points))
+}
+
+// Fetch the armed bomb of the URL and check the authenticated user is an opponent standing close to it
+func (c *BombConfig) checkDefuser(w http.ResponseWriter, r *http.Request) (*dbmodel.BombEntry, *dbmodel.GameEntry, *dbmodel.UserEntry, bool) {
+	bomb, ok := c.findVisibleBomb(w, r)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	if bomb.Status != dbmodel.BombStatusArmed {
+		w.WriteHeader(http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Bomb is not armed anymore"})
+		return nil, nil, nil, false
+	}
+
+	game, ok := c.findBombGame(w, r, bomb.IDGame)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	user, err := authentication.CurrentUser(r, c.UserRepository)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"error": "User not found"})
+		return nil, nil, nil, false
+	}
+	membership, err := c.GameRepository.FindByUserId(user.IDUser)
+	if err != nil || membership.IDGame != game.IDGame {
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "User is not part of this game"})
+		return nil, nil, nil, false
+	}
+	if *user.IDTeam == bomb.IDTeam {
+		w.WriteHeader(http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "Bombs can only be defused by an opposing team"})
+		return nil, nil, nil, false
+	}
+
+	position, err := c.PositionRepository.FindLast(game.IDGame, user.IDUser)
+	if err != nil || time.Since(position.RecordedAt) > positionMaxAge {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		render.JSON(w, r, map[string]string{"error": "No recent position reported in this game"})
+		return nil, nil, nil, false
+	}
+	distance := geo.Distance(geo.NewPoint(position.Lat, position.Long), bomb.Point())
+	if distance > game.Ruleset.DefuseRange() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		render.JSON(w, r, map[string]string{
+			"error": fmt.Sprintf("Too far from the bomb, %.0fm away for at most %.0fm", distance, game.Ruleset.DefuseRange()),
+		})
+		return nil, nil, nil, false
+	}
+
+	return bomb, game, user, true
+}
+
+func convertToDefusalResponse(defusal *dbmodel.DefusalEntry, points int) *model.DefusalResponse {
+	return &model.DefusalResponse{
+		BombId:      defusal.IDBomb,
+		IDUser:      defusal.IDUser,
+		IDTeam:      defusal.IDTeam,
+		StartedAt:   defusal.StartedAt,
+		ReadyAt:     defusal.ReadyAt,
+		ConfirmedAt: defusal.ConfirmedAt,
+		Points:      points,
+	}
+}
@@ -0,0 +1,244 @@
+package bomb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+var center = geo.Point{Lat: 48.85, Long: 2.35}
+
+// Running game where red placed a bomb at the center, blue defuses it
+type defuseFixture struct {
+	config *config.Config
+	db     *gorm.DB
+	game   *dbmodel.GameEntry
+	bomb   *dbmodel.BombEntry
+	tokens map[string]string // By player name
+	users  map[string]*dbmodel.UserEntry
+}
+
+func newDefuseFixture(t *testing.T) *defuseFixture {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	configuration, err := config.NewWithDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.JwtKey = "test"
+
+	f := &defuseFixture{config: configuration, db: db, tokens: map[string]string{}, users: map[string]*dbmodel.UserEntry{}}
+	f.game = &dbmodel.GameEntry{
+		CenterLatitude:  float32(center.Lat),
+		CenterLongitude: float32(center.Long),
+		Size:            500,
+		StartingDate:    time.Now().Add(-time.Hour),
+		EndingDate:      time.Now().Add(time.Hour),
+		Status:          dbmodel.GameStatusRunning,
+		Teams:           []dbmodel.TeamEntry{{Name: "red"}, {Name: "blue"}},
+	}
+	f.create(t, f.game)
+
+	teams := map[string]*uuid.UUID{"alice": &f.game.Teams[0].IDTeam, "carol": &f.game.Teams[0].IDTeam, "bob": &f.game.Teams[1].IDTeam, "dave": nil}
+	for name, team := range teams {
+		user := &dbmodel.UserEntry{IDUser: uuid.New(), UserName: name, Email: name + "@bombparty.com", IDTeam: team}
+		f.create(t, user)
+		token, err := authentication.GenerateToken(configuration.JwtKey, user.Email, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.users[name], f.tokens[name] = user, token
+	}
+
+	f.bomb = &dbmodel.BombEntry{
+		Lat:        float32(center.Lat),
+		Long:       float32(center.Long),
+		TypeBomb:   "classic",
+		IdUser:     f.users["alice"].IDUser,
+		IDGame:     f.game.IDGame,
+		IDTeam:     f.game.Teams[0].IDTeam,
+		PlacedAt:   time.Now(),
+		Fuse:       30,
+		DetonateAt: time.Now().Add(30 * time.Second),
+		Status:     dbmodel.BombStatusArmed,
+		Behaviour:  dbmodel.BombBehaviourTimed,
+	}
+	if err := db.Omit("Game").Create(f.bomb).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *defuseFixture) create(t *testing.T, value any) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Report the position of the player the distance north of the bomb
+func (f *defuseFixture) locate(t *testing.T, name string, meters float64, age time.Duration) {
+	t.Helper()
+	f.create(t, &dbmodel.PositionEntry{
+		IDGame:     f.game.IDGame,
+		IDUser:     f.users[name].IDUser,
+		Lat:        float32(center.Lat + meters/111195),
+		Long:       float32(center.Long),
+		RecordedAt: time.Now().Add(-age),
+	})
+}
+
+func (f *defuseFixture) post(t *testing.T, name, path string) (int, *model.DefusalResponse) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/"+strconv.Itoa(f.bomb.BombID)+path, nil)
+	r.Header.Set("Authorization", "Bearer "+f.tokens[name])
+	w := httptest.NewRecorder()
+	Routes(f.config).ServeHTTP(w, r)
+
+	res := &model.DefusalResponse{}
+	if w.Code < 300 {
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("invalid response %q: %s", w.Body.String(), err)
+		}
+	}
+	return w.Code, res
+}
+
+func TestStartDefuse(t *testing.T) {
+	tests := []struct {
+		name     string
+		player   string
+		distance float64 // Meters between the last position of the player and the bomb, -1 for no position
+		age      time.Duration
+		setup    func(t *testing.T, f *defuseFixture)
+		want     int
+	}{
+		{"opponent close by", "bob", 10, 0, nil, http.StatusCreated},
+		{"teammate of the owner", "carol", 10, 0, nil, http.StatusForbidden},
+		{"player of no game", "dave", 10, 0, nil, http.StatusForbidden},
+		{"too far", "bob", 30, 0, nil, http.StatusUnprocessableEntity},
+		{"stale position", "bob", 10, 3 * time.Minute, nil, http.StatusUnprocessableEntity},
+		{"no position hides the bomb", "bob", -1, 0, nil, http.StatusNotFound},
+		{"bomb already exploded", "bob", 10, 0, func(t *testing.T, f *defuseFixture) {
+			if err := f.db.Model(f.bomb).Update("status", dbmodel.BombStatusDetonated).Error; err != nil {
+				t.Fatal(err)
+			}
+		}, http.StatusConflict},
+		{"game paused", "bob", 10, 0, func(t *testing.T, f *defuseFixture) {
+			if err := f.db.Model(f.game).Update("status", dbmodel.GameStatusPaused).Error; err != nil {
+				t.Fatal(err)
+			}
+		}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDefuseFixture(t)
+			if tt.distance >= 0 {
+				f.locate(t, tt.player, tt.distance, tt.age)
+			}
+			if tt.setup != nil {
+				tt.setup(t, f)
+			}
+
+			code, res := f.post(t, tt.player, "/defuse")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if code == http.StatusCreated && res.ReadyAt.Sub(res.StartedAt) != 10*time.Second {
+				t.Errorf("defusal ready %s after its start, want 10s", res.ReadyAt.Sub(res.StartedAt))
+			}
+		})
+	}
+}
+
+func TestStartDefuseAgain(t *testing.T) {
+	f := newDefuseFixture(t)
+	f.locate(t, "bob", 5, 0)
+
+	_, first := f.post(t, "bob", "/defuse")
+	code, again := f.post(t, "bob", "/defuse")
+	if code != http.StatusOK || !again.ReadyAt.Equal(first.ReadyAt) {
+		t.Errorf("starting again = %d ready at %s, want %d ready at %s", code, again.ReadyAt, http.StatusOK, first.ReadyAt)
+	}
+}
+
+func TestConfirmDefuse(t *testing.T) {
+	tests := []struct {
+		name    string
+		started bool
+		ready   bool
+		moveTo  float64 // Meters from the bomb the player reports before confirming
+		want    int
+	}{
+		{"defusal done", true, true, 5, http.StatusOK},
+		{"not started", false, false, 5, http.StatusNotFound},
+		{"not done yet", true, false, 5, http.StatusConflict},
+		{"walked away", true, true, 40, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDefuseFixture(t)
+			f.locate(t, "bob", 5, time.Second)
+			if tt.started {
+				if code, _ := f.post(t, "bob", "/defuse"); code != http.StatusCreated {
+					t.Fatalf("starting the defusal = %d", code)
+				}
+			}
+			if tt.ready {
+				if err := f.db.Model(&dbmodel.DefusalEntry{}).Where("id_bomb = ?", f.bomb.BombID).
+					Update("ready_at", time.Now().Add(-time.Second)).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			f.locate(t, "bob", tt.moveTo, 0)
+
+			code, res := f.post(t, "bob", "/defuse/confirm")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+
+			bomb, err := f.config.BombRepository.FindById(f.bomb.BombID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			team, err := f.config.TeamRepository.FindById(f.game.Teams[1].IDTeam)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != http.StatusOK {
+				if bomb.Status != dbmodel.BombStatusArmed || team.Score != 0 {
+					t.Errorf("bomb %s and defusing team scored %d, want the bomb still armed and no points", bomb.Status, team.Score)
+				}
+				return
+			}
+			if bomb.Status != dbmodel.BombStatusDefused || bomb.DefusedBy == nil || *bomb.DefusedBy != f.users["bob"].IDUser {
+				t.Errorf("bomb %s by %v, want defused by bob", bomb.Status, bomb.DefusedBy)
+			}
+			if res.Points != 5 || team.Score != 5 {
+				t.Errorf("defusal scored %d and the team has %d, want 5", res.Points, team.Score)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package bomb
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+const (
+	defaultNearbyRadius = 500  // Meters
+	maxNearbyRadius     = 5000 // Meters
+)
+
+// GetNearbyBombs godoc
+// @Summary List the bombs near a point
+// @Description Get the bombs of the game of the authenticated user within the radius of a point, closest first, among the bombs they see
+// @Tags Bombs
+// @Security BearerAuth
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param long query number true "Longitude"
+// @Param radius query number false "Radius in meters, 500 by default, at most 5000"
+// @Success 200 {array} model.NearbyBombResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/bombs/nearby [get]
+func (c *BombConfig) GetNearbyBombs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid lat parameter, must be between -90 / 90"})
+		return
+	}
+	long, err := strconv.ParseFloat(query.Get("long"), 64)
+	if err != nil || long < -180 || long > 180 {
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid long parameter, must be between -180 / 180"})
+		return
+	}
+
+	radius := float64(defaultNearbyRadius)
+	if radiusStr := query.Get("radius"); radiusStr != "" {
+		radius, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 || radius > maxNearbyRadius {
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid radius parameter, must be between 0 and 5000 meters"})
+			return
+		}
+	}
+
+	game, ok := c.findCurrentGame(w, r)
+	if !ok {
+		return
+	}
+	if game == nil {
+		render.JSON(w, r, []model.NearbyBombResponse{})
+		return
+	}
+
+	viewer, ok := c.viewer(w, r)
+	if !ok {
+		return
+	}
+
+	center := geo.Point{Lat: lat, Long: long}
+	bombs, err := c.BombRepository.FindNearby(game.IDGame, geo.Circle{Center: center, Radius: radius})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Error fetching bombs"})
+		return
+	}
+	bombs = viewer.Filter(bombs)
+
+	responses := make([]model.NearbyBombResponse, len(bombs))
+	for i, bomb := range bombs {
+		responses[i] = model.NearbyBombResponse{
+			BombResponse: *convertToResponse(bomb),
+			Distance:     geo.Distance(center, bomb.Point()),
+		}
+	}
+	render.JSON(w, r, responses)
+}
@@ -19,11 +19,16 @@ func Routes(configuration *config.Config) chi.Router {
 
 		// Read
 		r.Get("/", bombConfig.GetAllBombs)
+		r.Get("/nearby", bombConfig.GetNearbyBombs)
 		r.Get("/{id}", bombConfig.GetBomb)
 		r.Get("/user/{userId}", bombConfig.GetBombsByUserId)
+		r.Get("/{id}/detonation", bombConfig.GetDetonation)
+		r.Get("/{id}/chain", bombConfig.GetChain)
 
 		// Update
 		r.Put("/{id}", bombConfig.UpdateBomb)
+		r.Post("/{id}/defuse", bombConfig.StartDefuse)
+		r.Post("/{id}/defuse/confirm", bombConfig.ConfirmDefuse)
 
 		// Delete
 		r.Delete("/{id}", bombConfig.DeleteBomb)
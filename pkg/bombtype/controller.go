@@ -0,0 +1,237 @@
+package bombtype
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+type BombTypeConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *BombTypeConfig {
+	return &BombTypeConfig{configuration}
+}
+
+// GetAllHandler godoc
+// @Summary      Get all bomb types
+// @Description  Retrieves the bomb type registry, disabled types included, sorted by type
+// @Tags         bomb types
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   model.BombTypeResponse
+// @Failure      500  {object}  map[string]string  "Failed to retrieve bomb types"
+// @Router       /api/v1/bomb-types [get]
+func (config *BombTypeConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	entries, err := config.BombTypeRepository.FindAll()
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find Bomb Types"})
+		return
+	}
+
+	res := []*model.BombTypeResponse{}
+	for _, entry := range entries {
+		res = append(res, convertToResponse(entry))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// GetByTypeHandler godoc
+// @Summary      Get a bomb type
+// @Description  Retrieves a bomb type of the registry
+// @Tags         bomb types
+// @Produce      json
+// @Param        type  path      string  true  "Bomb type"
+// @Security     BearerAuth
+// @Success      200  {object}  model.BombTypeResponse
+// @Failure      404  {object}  map[string]string  "Bomb type not found"
+// @Router       /api/v1/bomb-types/{type} [get]
+func (config *BombTypeConfig) GetByTypeHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findBombType(w, r)
+	if !ok {
+		return
+	}
+
+	render.JSON(w, r, convertToResponse(entry))
+}
+
+// PostHandler godoc
+// @Summary      Register a bomb type
+// @Description  Adds a bomb type players can hold and place, admins only
+// @Tags         bomb types
+// @Accept       json
+// @Produce      json
+// @Param        bombType  body      model.BombTypeRequest  true  "Bomb type payload"
+// @Security     BearerAuth
+// @Success      201  {object}  model.BombTypeResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload"
+// @Failure      403  {object}  map[string]string  "Admins only"
+// @Failure      409  {object}  map[string]string  "Bomb type already registered"
+// @Failure      500  {object}  map[string]string  "Failed to create bomb type"
+// @Router       /api/v1/bomb-types [post]
+func (config *BombTypeConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
+
+	req := &model.BombTypeRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Bomb Type request payload. " + err.Error()})
+		return
+	}
+	if err := req.ValidateCreate(); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+
+	_, err := config.BombTypeRepository.FindByType(req.TypeBomb)
+	if err == nil {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Bomb type " + req.TypeBomb + " already exists"})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Create Bomb Type"})
+		return
+	}
+
+	entry := &dbmodel.BombTypeEntry{TypeBomb: req.TypeBomb}
+	fillBombType(entry, req)
+
+	entry, err = config.BombTypeRepository.Create(entry)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Create Bomb Type"})
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, convertToResponse(entry))
+}
+
+// UpdateHandler godoc
+// @Summary      Update a bomb type
+// @Description  Replaces the settings of a bomb type, admins only. Armed bombs explode with the new blast
+// @Tags         bomb types
+// @Accept       json
+// @Produce      json
+// @Param        type      path      string                 true  "Bomb type"
+// @Param        bombType  body      model.BombTypeRequest  true  "Bomb type payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.BombTypeResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload"
+// @Failure      403  {object}  map[string]string  "Admins only"
+// @Failure      404  {object}  map[string]string  "Bomb type not found"
+// @Failure      500  {object}  map[string]string  "Failed to update bomb type"
+// @Router       /api/v1/bomb-types/{type} [put]
+func (config *BombTypeConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findBombType(w, r)
+	if !ok {
+		return
+	}
+
+	req := &model.BombTypeRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Bomb Type request payload. " + err.Error()})
+		return
+	}
+	fillBombType(entry, req)
+
+	entry, err := config.BombTypeRepository.Update(entry)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Update Bomb Type"})
+		return
+	}
+
+	render.JSON(w, r, convertToResponse(entry))
+}
+
+// DeleteHandler godoc
+// @Summary      Delete a bomb type
+// @Description  Removes a bomb type no bomb was placed with, along with its inventory entries, admins only
+// @Tags         bomb types
+// @Produce      json
+// @Param        type  path      string  true  "Bomb type"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Bomb type deleted successfully"
+// @Failure      403  {object}  map[string]string  "Admins only"
+// @Failure      404  {object}  map[string]string  "Bomb type not found"
+// @Failure      409  {object}  map[string]string  "Bombs of this type were placed, disable it instead"
+// @Failure      500  {object}  map[string]string  "Failed to delete bomb type"
+// @Router       /api/v1/bomb-types/{type} [delete]
+func (config *BombTypeConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+
+	err := config.BombTypeRepository.Delete(chi.URLParam(r, "type"))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Bomb type not found in the DB"})
+		return
+	case errors.Is(err, dbmodel.ErrBombTypeInUse):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Bombs of this type were placed, disable it instead"})
+		return
+	case err != nil:
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Delete Bomb Type"})
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Bomb type deleted successfully"})
+}
+
+func (config *BombTypeConfig) findBombType(w http.ResponseWriter, r *http.Request) (*dbmodel.BombTypeEntry, bool) {
+
+	entry, err := config.BombTypeRepository.FindByType(chi.URLParam(r, "type"))
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Bomb type not found in the DB"})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+func fillBombType(entry *dbmodel.BombTypeEntry, req *model.BombTypeRequest) {
+	entry.Name = req.Name
+	entry.Radius = req.Radius
+	entry.Damage = req.Damage
+	entry.Fuse = req.Fuse
+	entry.Rarity = dbmodel.BombRarity(req.Rarity)
+	entry.Enabled = req.Enabled == nil || *req.Enabled
+	entry.Behaviour = dbmodel.BombBehaviourTimed
+	if req.Behaviour != "" {
+		entry.Behaviour = dbmodel.BombBehaviour(req.Behaviour)
+	}
+	entry.TriggerRadius = req.TriggerRadius
+}
+
+func convertToResponse(entry *dbmodel.BombTypeEntry) *model.BombTypeResponse {
+	return &model.BombTypeResponse{
+		TypeBomb:      entry.TypeBomb,
+		Name:          entry.Name,
+		Radius:        entry.Radius,
+		Damage:        entry.Damage,
+		Fuse:          entry.Fuse,
+		Rarity:        string(entry.Rarity),
+		Enabled:       entry.Enabled,
+		Behaviour:     string(entry.Behaviour),
+		TriggerRadius: entry.TriggerRadius,
+		UpdatedAt:     entry.UpdatedAt,
+	}
+}
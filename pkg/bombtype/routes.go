@@ -0,0 +1,34 @@
+package bombtype
+
+import (
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init Router
+	bombTypeConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(configuration.JwtKey))
+
+		router.Get("/", bombTypeConfig.GetAllHandler)
+		router.Get("/{type}", bombTypeConfig.GetByTypeHandler)
+
+		// Only admins manage the registry
+		router.Group(func(router chi.Router) {
+			router.Use(authentication.AdminMiddleware(configuration.UserRepository))
+
+			router.Post("/", bombTypeConfig.PostHandler)
+			router.Put("/{type}", bombTypeConfig.UpdateHandler)
+			router.Delete("/{type}", bombTypeConfig.DeleteHandler)
+		})
+	})
+
+	return router
+}
@@ -0,0 +1,26 @@
+package detonation
+
+import (
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+)
+
+// Effect of a bomb type when it explodes
+type Blast struct {
+	Radius float64 // Meters
+	Damage int
+	Fuse   time.Duration
+}
+
+// Blast of the registered bomb type
+func BlastOf(bombType *dbmodel.BombTypeEntry) Blast {
+	return Blast{
+		Radius: bombType.Radius,
+		Damage: bombType.Damage,
+		Fuse:   time.Duration(bombType.Fuse) * time.Second,
+	}
+}
+
+// Bombs whose type is missing from the registry explode like a classic bomb
+var fallback = BlastOf(&dbmodel.DefaultBombTypes[0])
@@ -0,0 +1,110 @@
+package detonation
+
+import (
+	"container/heap"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+// Detonations a single chain reaction may hold, the bombs left out keep their own fuse
+const MaxChainLength = 50
+
+// Bombs of a chain reaction, counted to stop it at MaxChainLength
+type chainState struct {
+	size    int // Detonations recorded or scheduled, the origin included
+	pending int // Bombs scheduled and not fired yet
+}
+
+// Detonate the bomb, whose blast then sets off the armed bombs of its game it reaches
+func (s *Scheduler) Detonate(bomb *dbmodel.BombEntry, at time.Time) (*dbmodel.DetonationEntry, error) {
+	return s.resolve(bomb, at, nil)
+}
+
+// Detonate the bomb in its game, trigger is the detonation that set it off, nil for the origin of a chain
+func (s *Scheduler) resolve(bomb *dbmodel.BombEntry, at time.Time, trigger *dbmodel.DetonationEntry) (*dbmodel.DetonationEntry, error) {
+	game, err := s.games.FindById(bomb.IDGame)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		game = nil
+	case err != nil:
+		return nil, err
+	case game.Status == dbmodel.GameStatusPaused:
+		return nil, ErrGamePaused
+	}
+
+	detonation, err := s.detonate(game, bomb, at, trigger, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.spread(game, bomb, detonation)
+	return detonation, nil
+}
+
+// Schedule the armed bombs of the game caught in the blast to explode the chain delay of the game after it,
+// closest first then by id. They go through the queue like any fuse, so a bomb defused in the meantime stays put.
+// A bomb is set off once whatever the blasts that reach it, and the chain stops growing at MaxChainLength
+func (s *Scheduler) spread(game *dbmodel.GameEntry, bomb *dbmodel.BombEntry, detonation *dbmodel.DetonationEntry) {
+	// Bombs without game have nothing around them to set off
+	if game == nil {
+		return
+	}
+
+	blast := geo.Circle{Center: bomb.Point(), Radius: detonation.Radius}
+	nearby, err := s.bombs.FindNearby(game.IDGame, blast)
+	if err != nil {
+		log.Printf("Failed to find the bombs reached by bomb %d: %s\n", bomb.BombID, err.Error())
+		return
+	}
+	at := detonation.DetonatedAt.Add(game.Ruleset.ChainHop())
+
+	s.mu.Lock()
+	chain, ok := s.chains[detonation.IDChain]
+	if !ok {
+		chain = &chainState{size: 1}
+		s.chains[detonation.IDChain] = chain
+	}
+	for _, next := range nearby {
+		if next.BombID == bomb.BombID || next.Status != dbmodel.BombStatusArmed || s.reached[next.BombID] {
+			continue
+		}
+		if chain.size == MaxChainLength {
+			log.Printf("Chain reaction %d stopped at %d detonations\n", detonation.IDChain, MaxChainLength)
+			break
+		}
+		s.reached[next.BombID] = true
+		chain.size++
+		chain.pending++
+		s.seq++
+		heap.Push(&s.queue, fuse{idBomb: next.BombID, at: at, trigger: detonation, seq: s.seq})
+	}
+	if chain.pending == 0 {
+		delete(s.chains, detonation.IDChain)
+	}
+	s.mu.Unlock()
+
+	s.wakeUp()
+}
+
+// Forget the bomb of a chain once it has exploded or cannot anymore, and the chain once none is left
+func (s *Scheduler) release(f fuse) {
+	if f.trigger == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reached, f.idBomb)
+	if chain, ok := s.chains[f.trigger.IDChain]; ok {
+		chain.pending--
+		if chain.pending == 0 {
+			delete(s.chains, f.trigger.IDChain)
+		}
+	}
+}
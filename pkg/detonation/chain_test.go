@@ -0,0 +1,116 @@
+package detonation
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+)
+
+// Bombs waiting in the queue, in firing order
+func queued(s *Scheduler) []int {
+	ids := []int{}
+	for _, f := range s.popDue(time.Now().Add(time.Hour)) {
+		ids = append(ids, f.idBomb)
+		s.push(f)
+	}
+	return ids
+}
+
+func TestChainReaction(t *testing.T) {
+	s, db := newTestScheduler(t)
+	game := createGame(t, db, dbmodel.GameStatusRunning)
+	red, blue := game.Teams[0].IDTeam, game.Teams[1].IDTeam
+
+	// Classic bombs blast 25 meters around them
+	origin := createBomb(t, db, game, red, "classic", center)
+	far := createBomb(t, db, game, blue, "classic", north(center, 20))
+	near := createBomb(t, db, game, red, "classic", north(center, 10))
+	outside := createBomb(t, db, game, blue, "classic", north(center, 40))
+	defused := createBomb(t, db, game, blue, "classic", north(center, 5))
+	if err := db.Model(defused).Update("status", dbmodel.BombStatusDefused).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	detonation, err := s.Detonate(origin, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := queued(s), []int{near.BombID, far.BombID}; !slices.Equal(got, want) {
+		t.Fatalf("bombs set off by the origin = %v, want %v closest first", got, want)
+	}
+	if at := detonation.DetonatedAt.Add(game.Ruleset.ChainHop()); !s.queue[0].at.Equal(at) {
+		t.Errorf("chained bombs explode at %s, want %s", s.queue[0].at, at)
+	}
+
+	// Another blast of the chain reaching the same bombs sets them off once
+	s.spread(game, origin, detonation)
+	if got := queued(s); len(got) != 2 {
+		t.Errorf("bombs queued after a second blast = %v, want 2", got)
+	}
+
+	// The far bomb then reaches the one outside of the first blast
+	for _, f := range s.popDue(time.Now().Add(time.Hour)) {
+		s.fire(f)
+	}
+	if got, want := queued(s), []int{outside.BombID}; !slices.Equal(got, want) {
+		t.Fatalf("bombs set off by the second hop = %v, want %v", got, want)
+	}
+	for _, f := range s.popDue(time.Now().Add(time.Hour)) {
+		s.fire(f)
+	}
+
+	chain, err := s.detonations.FindChain(origin.BombID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hops := map[int]int{}
+	for _, entry := range chain {
+		hops[entry.IDBomb] = entry.Hop
+	}
+	want := map[int]int{origin.BombID: 0, near.BombID: 1, far.BombID: 1, outside.BombID: 2}
+	if len(hops) != len(want) {
+		t.Errorf("chain = %v, want %v", hops, want)
+	}
+	for id, hop := range want {
+		if got, ok := hops[id]; !ok || got != hop {
+			t.Errorf("bomb %d exploded at hop %d (%v), want hop %d", id, got, ok, hop)
+		}
+	}
+	if got := bombStatus(t, db, defused.BombID); got != dbmodel.BombStatusDefused {
+		t.Errorf("defused bomb status = %s, want %s", got, dbmodel.BombStatusDefused)
+	}
+	if len(s.reached) != 0 || len(s.chains) != 0 {
+		t.Errorf("scheduler still tracks %d bombs and %d chains once the chain is over", len(s.reached), len(s.chains))
+	}
+}
+
+func TestChainReactionLength(t *testing.T) {
+	s, db := newTestScheduler(t)
+	game := createGame(t, db, dbmodel.GameStatusRunning)
+	origin := createBomb(t, db, game, game.Teams[0].IDTeam, "classic", center)
+	for i := 0; i < MaxChainLength+5; i++ {
+		createBomb(t, db, game, game.Teams[1].IDTeam, "classic", north(center, 1))
+	}
+
+	detonation, err := s.Detonate(origin, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.queue.Len(); got != MaxChainLength-1 {
+		t.Errorf("bombs set off = %d, want %d", got, MaxChainLength-1)
+	}
+	chain := s.chains[detonation.IDChain]
+	if chain == nil || chain.size != MaxChainLength || chain.pending != MaxChainLength-1 {
+		t.Errorf("chain = %+v, want %d detonations of which %d pending", chain, MaxChainLength, MaxChainLength-1)
+	}
+
+	// The bombs left out keep their own fuse
+	for _, f := range s.popDue(time.Now().Add(time.Hour)) {
+		s.release(f)
+	}
+	if len(s.reached) != 0 || len(s.chains) != 0 {
+		t.Errorf("scheduler still tracks %d bombs and %d chains once released", len(s.reached), len(s.chains))
+	}
+}
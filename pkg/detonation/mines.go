@@ -0,0 +1,48 @@
+package detonation
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+// Set off the armed mines of the opponents of the player whose trigger radius the position is in, closest first,
+// and return their detonations. The bombs their blasts reach explode later, as chain reactions.
+// Mines only go off in running games, for recent positions recorded after they were placed.
+// Failures are only logged, the position is stored whatever happens to the mines
+func (s *Scheduler) TripMines(game *dbmodel.GameEntry, player *dbmodel.UserEntry, position *dbmodel.PositionEntry) []*dbmodel.DetonationEntry {
+	detonations := []*dbmodel.DetonationEntry{}
+	now := time.Now()
+	if game.Status != dbmodel.GameStatusRunning || player.IDTeam == nil || position.RecordedAt.Before(now.Add(-hitWindow)) {
+		return detonations
+	}
+
+	mines, err := s.bombs.FindMinesTriggered(game.IDGame, geo.NewPoint(position.Lat, position.Long))
+	if err != nil {
+		log.Printf("Failed to find the mines around position %d: %s\n", position.IDPosition, err.Error())
+		return detonations
+	}
+
+	for _, mine := range mines {
+		// Players walk past the mines of their own team
+		if mine.IDTeam == *player.IDTeam || mine.IdUser == player.IDUser || position.RecordedAt.Before(mine.PlacedAt) {
+			continue
+		}
+
+		detonation, err := s.detonate(game, mine, now, nil, &player.IDUser)
+		if errors.Is(err, dbmodel.ErrBombNotArmed) {
+			// Already set off by another player
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to detonate mine %d tripped by %s: %s\n", mine.BombID, player.IDUser, err.Error())
+			continue
+		}
+		s.spread(game, mine, detonation)
+		detonations = append(detonations, detonation)
+	}
+	return detonations
+}
@@ -0,0 +1,99 @@
+package detonation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+func TestTripMines(t *testing.T) {
+	tests := []struct {
+		name       string
+		gameStatus dbmodel.GameStatus
+		ownMine    bool          // Mine of the team of the player
+		distance   float64       // Meters between the mine and the position
+		age        time.Duration // Of the position
+		before     bool          // Position recorded before the mine was placed
+		want       bool
+	}{
+		{"opponent walks in", dbmodel.GameStatusRunning, false, 2, 0, false, true},
+		{"teammate walks past", dbmodel.GameStatusRunning, true, 2, 0, false, false},
+		{"outside the trigger radius", dbmodel.GameStatusRunning, false, 10, 0, false, false},
+		{"stale position", dbmodel.GameStatusRunning, false, 2, 3 * time.Minute, false, false},
+		{"position older than the mine", dbmodel.GameStatusRunning, false, 2, 0, true, false},
+		{"paused game", dbmodel.GameStatusPaused, false, 2, 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestScheduler(t)
+			game := createGame(t, db, tt.gameStatus)
+			red, blue := game.Teams[0].IDTeam, game.Teams[1].IDTeam
+
+			owner := blue
+			if tt.ownMine {
+				owner = red
+			}
+			mine := createBomb(t, db, game, owner, "mine", center)
+			player := &dbmodel.UserEntry{IDUser: uuid.New(), IDTeam: &red}
+
+			recordedAt := time.Now().Add(-tt.age)
+			if tt.before {
+				recordedAt = mine.PlacedAt.Add(-time.Second)
+			}
+			p := north(center, tt.distance)
+			position := &dbmodel.PositionEntry{IDGame: game.IDGame, IDUser: player.IDUser, Lat: float32(p.Lat), Long: float32(p.Long), RecordedAt: recordedAt}
+
+			detonations := s.TripMines(game, player, position)
+			if tripped := len(detonations) == 1; tripped != tt.want || len(detonations) > 1 {
+				t.Fatalf("TripMines() set off %d mines, want tripped %v", len(detonations), tt.want)
+			}
+			want := dbmodel.BombStatusArmed
+			if tt.want {
+				want = dbmodel.BombStatusDetonated
+				if by := detonations[0].TrippedBy; by == nil || *by != player.IDUser {
+					t.Errorf("mine tripped by %v, want %s", by, player.IDUser)
+				}
+			}
+			if got := bombStatus(t, db, mine.BombID); got != want {
+				t.Errorf("mine status = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestTripMinesChain(t *testing.T) {
+	s, db := newTestScheduler(t)
+	game := createGame(t, db, dbmodel.GameStatusRunning)
+	red, blue := game.Teams[0].IDTeam, game.Teams[1].IDTeam
+
+	// A mine blasts 15 meters around it and sets off the bombs it reaches like any other blast
+	mine := createBomb(t, db, game, blue, "mine", center)
+	nearby := createBomb(t, db, game, blue, "classic", north(center, 10))
+	other := createBomb(t, db, game, blue, "mine", north(center, 12))
+	createBomb(t, db, game, blue, "classic", geo.Point{Lat: center.Lat, Long: center.Long + 0.001})
+
+	player := &dbmodel.UserEntry{IDUser: uuid.New(), IDTeam: &red}
+	position := &dbmodel.PositionEntry{IDGame: game.IDGame, IDUser: player.IDUser, Lat: float32(center.Lat), Long: float32(center.Long), RecordedAt: time.Now()}
+	detonations := s.TripMines(game, player, position)
+	if len(detonations) != 1 || detonations[0].IDBomb != mine.BombID {
+		t.Fatalf("TripMines() = %v, want the mine only", detonations)
+	}
+
+	// Mines reached by a blast explode instead of waiting for a player
+	got := queued(s)
+	if len(got) != 2 || got[0] != nearby.BombID || got[1] != other.BombID {
+		t.Errorf("bombs set off by the mine = %v, want [%d %d]", got, nearby.BombID, other.BombID)
+	}
+	for _, f := range s.popDue(time.Now().Add(time.Hour)) {
+		s.fire(f)
+	}
+	for _, bomb := range []*dbmodel.BombEntry{nearby, other} {
+		if got := bombStatus(t, db, bomb.BombID); got != dbmodel.BombStatusDetonated {
+			t.Errorf("bomb %d status = %s, want %s", bomb.BombID, got, dbmodel.BombStatusDetonated)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package detonation
+
+import (
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+)
+
+type fuse struct {
+	idBomb int
+	at     time.Time
+
+	// Detonation whose blast set the bomb off, nil for a fuse burning on its own
+	trigger *dbmodel.DetonationEntry
+	// Order of the bombs set off at the same time, closest to the blast first
+	seq uint64
+}
+
+// Min-heap of fuses ordered by detonation time, see container/heap
+type fuseQueue []fuse
+
+func (q fuseQueue) Len() int { return len(q) }
+
+func (q fuseQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	if q[i].seq != q[j].seq {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].idBomb < q[j].idBomb
+}
+
+func (q fuseQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *fuseQueue) Push(x any) { *q = append(*q, x.(fuse)) }
+
+func (q *fuseQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,318 @@
+// Package detonation arms the placed bombs and makes them explode once their fuse has burnt.
+package detonation
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/eventlog"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/hexgrid"
+)
+
+// Delay before checking again a bomb whose game is paused
+const pausedRetry = 10 * time.Second
+
+// Positions older than this are too stale to tell whether a player was caught in a blast
+const hitWindow = 2 * time.Minute
+
+var ErrGamePaused = errors.New("game is paused")
+
+type Scheduler struct {
+	bombs       dbmodel.BombRepository
+	types       dbmodel.BombTypeRepository
+	games       dbmodel.GameRepository
+	detonations dbmodel.DetonationRepository
+	territory   dbmodel.TerritoryRepository
+	users       dbmodel.UserRepository
+	positions   dbmodel.PositionRepository
+	events      *eventlog.Log
+
+	mu      sync.Mutex
+	queue   fuseQueue
+	seq     uint64
+	reached map[int]bool        // Bombs scheduled by a chain reaction
+	chains  map[int]*chainState // Chain reactions still spreading, by id
+	wake    chan struct{}
+}
+
+func New(bombs dbmodel.BombRepository, types dbmodel.BombTypeRepository, games dbmodel.GameRepository,
+	detonations dbmodel.DetonationRepository, territory dbmodel.TerritoryRepository,
+	users dbmodel.UserRepository, positions dbmodel.PositionRepository, events *eventlog.Log) *Scheduler {
+	return &Scheduler{
+		bombs:       bombs,
+		types:       types,
+		games:       games,
+		detonations: detonations,
+		territory:   territory,
+		users:       users,
+		positions:   positions,
+		events:      events,
+		reached:     map[int]bool{},
+		chains:      map[int]*chainState{},
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Re-arm the pending bombs stored in the database and start detonating them
+func (s *Scheduler) Start() error {
+	bombs, err := s.bombs.FindArmed()
+	if err != nil {
+		return err
+	}
+
+	for _, bomb := range bombs {
+		s.Arm(bomb)
+	}
+	go s.run()
+
+	log.Printf("Detonation scheduler started with %d armed bombs\n", len(bombs))
+	return nil
+}
+
+// Schedule the detonation of a bomb, bombs already due explode right away
+func (s *Scheduler) Arm(bomb *dbmodel.BombEntry) {
+	s.schedule(bomb.BombID, bomb.DetonateAt)
+}
+
+// Resolve the blast of the bomb and record it, crediting the team of its owner.
+// The points are the damage of the bomb, or the players hit when the ruleset of the game scores hits.
+// The trigger is the detonation whose blast set the bomb off, nil when its fuse has burnt or a player tripped it
+func (s *Scheduler) detonate(game *dbmodel.GameEntry, bomb *dbmodel.BombEntry, at time.Time, trigger *dbmodel.DetonationEntry, trippedBy *uuid.UUID) (*dbmodel.DetonationEntry, error) {
+	blast, err := s.blastOf(bomb.TypeBomb)
+	if err != nil {
+		return nil, err
+	}
+	point := bomb.Point()
+	entry := &dbmodel.DetonationEntry{
+		IDBomb:      bomb.BombID,
+		DetonatedAt: at,
+		Lat:         bomb.Lat,
+		Long:        bomb.Long,
+		Radius:      blast.Radius,
+		Damage:      blast.Damage,
+		IDChain:     bomb.BombID,
+		TrippedBy:   trippedBy,
+	}
+	if trigger != nil {
+		entry.IDChain = trigger.IDChain
+		entry.IDTrigger = &trigger.IDBomb
+		entry.Hop = trigger.Hop + 1
+	}
+
+	// Bombs left without game explode for nothing
+	scoring := false
+	if game != nil {
+		entry.IDGame = &game.IDGame
+		if bomb.IDTeam != uuid.Nil {
+			entry.IDTeam = &bomb.IDTeam
+		}
+
+		// Points only count while scores can move and for bombs inside the zone in force
+		scoring = entry.IDTeam != nil && game.AcceptsScoreChanges() && game.PlayAreaAt(at).Contains(point)
+
+		// Tripped mines always record who they caught
+		opponents, teammates := 0, 0
+		if scoring && (game.Ruleset.ScoresHits() || trippedBy != nil) {
+			opponents, teammates, err = s.hits(game, bomb, geo.Circle{Center: point, Radius: blast.Radius}, at)
+			if err != nil {
+				return nil, err
+			}
+			entry.Hits = opponents
+			if game.Ruleset.FriendlyFire {
+				entry.Hits += teammates
+			}
+		}
+		switch {
+		case scoring && game.Ruleset.ScoresHits():
+			entry.Points = game.Ruleset.HitPoints(opponents, teammates)
+		case scoring:
+			entry.Points = blast.Damage
+		}
+	}
+
+	var score *dbmodel.ScoreEventEntry
+	if scoring && entry.Points != 0 {
+		score = &dbmodel.ScoreEventEntry{
+			IDGame: game.IDGame,
+			IDTeam: *entry.IDTeam,
+			IDUser: &bomb.IdUser,
+			Reason: dbmodel.ScoreReasonDetonation,
+			Points: entry.Points,
+			IDBomb: &bomb.BombID,
+			At:     at,
+		}
+		if game.Ruleset.ScoresHits() {
+			score.Objective = fmt.Sprintf("%d players hit", entry.Hits)
+		}
+	}
+
+	entry, err = s.detonations.Record(entry, score)
+	if err != nil {
+		return nil, err
+	}
+
+	if game != nil {
+		s.events.BombDetonated(bomb, entry)
+		if score != nil {
+			s.events.ScoreChanged(score)
+		}
+	}
+
+	// Territory games hand the cells hit by the blast over to the team
+	if scoring && game.Mode == dbmodel.GameModeTerritory {
+		if err := s.territory.Capture(game.IDGame, blastCells(game, point, blast, at), *entry.IDTeam, at); err != nil {
+			log.Printf("Failed to capture territory for bomb %d: %s\n", bomb.BombID, err.Error())
+		}
+	}
+
+	return entry, nil
+}
+
+func (s *Scheduler) blastOf(typeBomb string) (Blast, error) {
+	bombType, err := s.types.FindByType(typeBomb)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return Blast{}, err
+	}
+	return BlastOf(bombType), nil
+}
+
+// Opponents and teammates of the owner whose last recent position is inside the blast.
+// The owner is never caught by their own bomb
+func (s *Scheduler) hits(game *dbmodel.GameEntry, bomb *dbmodel.BombEntry, blast geo.Circle, at time.Time) (int, int, error) {
+	players, err := s.users.FindByGame(game.IDGame)
+	if err != nil {
+		return 0, 0, err
+	}
+	positions, err := s.positions.FindLatest(game.IDGame, at.Add(-hitWindow))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	teams := map[uuid.UUID]uuid.UUID{}
+	for _, player := range players {
+		if player.IDTeam != nil {
+			teams[player.IDUser] = *player.IDTeam
+		}
+	}
+
+	opponents, teammates := 0, 0
+	for _, position := range positions {
+		team, playing := teams[position.IDUser]
+		if !playing || position.IDUser == bomb.IdUser || !blast.Contains(geo.NewPoint(position.Lat, position.Long)) {
+			continue
+		}
+		if team == bomb.IDTeam {
+			teammates++
+		} else {
+			opponents++
+		}
+	}
+	return opponents, teammates, nil
+}
+
+// Cells of the play area reached by the blast, at least the one of the bomb
+func blastCells(game *dbmodel.GameEntry, point geo.Point, blast Blast, at time.Time) []hexgrid.Cell {
+	grid := game.Grid()
+	area := game.PlayAreaAt(at)
+
+	cells := []hexgrid.Cell{grid.CellOf(point)}
+	for _, cell := range grid.Cover(geo.Circle{Center: point, Radius: blast.Radius}) {
+		if cell != cells[0] && area.Contains(grid.Center(cell)) {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+func (s *Scheduler) schedule(idBomb int, at time.Time) {
+	s.push(fuse{idBomb: idBomb, at: at})
+}
+
+func (s *Scheduler) push(f fuse) {
+	s.mu.Lock()
+	heap.Push(&s.queue, f)
+	s.mu.Unlock()
+
+	s.wakeUp()
+}
+
+// Let the loop recompute its next wake up
+func (s *Scheduler) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	for {
+		s.mu.Lock()
+		wait := time.Hour
+		if s.queue.Len() > 0 {
+			wait = max(time.Until(s.queue[0].at), 0)
+		}
+		s.mu.Unlock()
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+			for _, f := range s.popDue(time.Now()) {
+				s.fire(f)
+			}
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *Scheduler) popDue(now time.Time) []fuse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []fuse
+	for s.queue.Len() > 0 && !s.queue[0].at.After(now) {
+		due = append(due, heap.Pop(&s.queue).(fuse))
+	}
+	return due
+}
+
+func (s *Scheduler) fire(f fuse) {
+	// The bomb may have been removed, defused or already detonated since it was armed or set off
+	bomb, err := s.bombs.FindById(f.idBomb)
+	if err != nil || bomb.Status != dbmodel.BombStatusArmed {
+		s.release(f)
+		return
+	}
+
+	// Nobody walked into the mine in time
+	if bomb.IsMine() && f.trigger == nil {
+		if err := s.bombs.Expire(f.idBomb); err != nil && !errors.Is(err, dbmodel.ErrBombNotArmed) {
+			log.Printf("Failed to expire mine %d: %s\n", f.idBomb, err.Error())
+		}
+		return
+	}
+
+	_, err = s.resolve(bomb, time.Now(), f.trigger)
+	switch {
+	case errors.Is(err, ErrGamePaused):
+		// Bombs set off by a chain keep their place in it until the game resumes
+		f.at = time.Now().Add(pausedRetry)
+		s.push(f)
+		return
+	case err != nil && !errors.Is(err, dbmodel.ErrBombNotArmed):
+		log.Printf("Failed to detonate bomb %d: %s\n", f.idBomb, err.Error())
+	}
+	s.release(f)
+}
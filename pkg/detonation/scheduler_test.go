@@ -0,0 +1,182 @@
+package detonation
+
+import (
+	"container/heap"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bombparty.com/bombparty-api/database"
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/eventlog"
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+var center = geo.Point{Lat: 48.85, Long: 2.35}
+
+// Scheduler working on a fresh in-memory database, returned to set up the tests
+func newTestScheduler(t *testing.T) (*Scheduler, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	database.Migrate(db)
+
+	s := New(dbmodel.NewBombRepository(db), dbmodel.NewBombTypeRepository(db), dbmodel.NewGameRepository(db),
+		dbmodel.NewDetonationRepository(db), dbmodel.NewTerritoryRepository(db),
+		dbmodel.NewUserRepository(db), dbmodel.NewPositionRepository(db), eventlog.New(dbmodel.NewGameEventRepository(db)))
+	return s, db
+}
+
+// Game around the center with a red and a blue team
+func createGame(t *testing.T, db *gorm.DB, status dbmodel.GameStatus) *dbmodel.GameEntry {
+	t.Helper()
+	game := &dbmodel.GameEntry{
+		CenterLatitude:  float32(center.Lat),
+		CenterLongitude: float32(center.Long),
+		Size:            500,
+		StartingDate:    time.Now().Add(-time.Hour),
+		EndingDate:      time.Now().Add(time.Hour),
+		Status:          status,
+		Teams:           []dbmodel.TeamEntry{{Name: "red"}, {Name: "blue"}},
+	}
+	if err := db.Create(game).Error; err != nil {
+		t.Fatal(err)
+	}
+	return game
+}
+
+// Armed bomb of the team placed a minute ago, mines take the trigger radius of their default type
+func createBomb(t *testing.T, db *gorm.DB, game *dbmodel.GameEntry, idTeam uuid.UUID, typeBomb string, p geo.Point) *dbmodel.BombEntry {
+	t.Helper()
+	bomb := &dbmodel.BombEntry{
+		Lat:        float32(p.Lat),
+		Long:       float32(p.Long),
+		TypeBomb:   typeBomb,
+		IdUser:     uuid.New(),
+		IDGame:     game.IDGame,
+		IDTeam:     idTeam,
+		PlacedAt:   time.Now().Add(-time.Minute),
+		Fuse:       30,
+		DetonateAt: time.Now().Add(-30 * time.Second),
+		Status:     dbmodel.BombStatusArmed,
+		Behaviour:  dbmodel.BombBehaviourTimed,
+	}
+	if typeBomb == "mine" {
+		bomb.Behaviour = dbmodel.BombBehaviourMine
+		bomb.TriggerRadius = 5
+	}
+	if err := db.Omit("Game").Create(bomb).Error; err != nil {
+		t.Fatal(err)
+	}
+	return bomb
+}
+
+func bombStatus(t *testing.T, db *gorm.DB, idBomb int) dbmodel.BombStatus {
+	t.Helper()
+	var bomb dbmodel.BombEntry
+	if err := db.First(&bomb, idBomb).Error; err != nil {
+		t.Fatal(err)
+	}
+	return bomb.Status
+}
+
+// Point the distance north of p, a degree of latitude spans about 111195 meters
+func north(p geo.Point, meters float64) geo.Point {
+	return geo.Point{Lat: p.Lat + meters/111195, Long: p.Long}
+}
+
+func TestPopDue(t *testing.T) {
+	now := time.Now()
+	s := New(nil, nil, nil, nil, nil, nil, nil, nil)
+	for _, f := range []fuse{
+		{idBomb: 2, at: now.Add(time.Second)},
+		{idBomb: 7, at: now.Add(-time.Second), seq: 2},
+		{idBomb: 3, at: now},
+		{idBomb: 9, at: now.Add(-time.Second), seq: 1},
+		{idBomb: 1, at: now.Add(-2 * time.Second)},
+	} {
+		heap.Push(&s.queue, f)
+	}
+
+	got := []int{}
+	for _, f := range s.popDue(now) {
+		got = append(got, f.idBomb)
+	}
+	if want := []int{1, 9, 7, 3}; !slices.Equal(got, want) {
+		t.Errorf("popDue() = %v, want %v", got, want)
+	}
+	if s.queue.Len() != 1 || s.queue[0].idBomb != 2 {
+		t.Errorf("queue left with %v, want bomb 2 only", s.queue)
+	}
+	if due := s.popDue(now); len(due) != 0 {
+		t.Errorf("popDue() = %v once the due bombs are gone, want none", due)
+	}
+}
+
+func TestFire(t *testing.T) {
+	tests := []struct {
+		name       string
+		gameStatus dbmodel.GameStatus
+		typeBomb   string
+		bombStatus dbmodel.BombStatus // Status of the bomb before it fires
+		chained    bool               // Set off by the blast of another bomb
+		want       dbmodel.BombStatus
+		wantQueued bool // Fuse pushed back for later
+	}{
+		{"armed bomb explodes", dbmodel.GameStatusRunning, "classic", dbmodel.BombStatusArmed, false, dbmodel.BombStatusDetonated, false},
+		{"defused bomb stays put", dbmodel.GameStatusRunning, "classic", dbmodel.BombStatusDefused, false, dbmodel.BombStatusDefused, false},
+		{"paused game delays the bomb", dbmodel.GameStatusPaused, "classic", dbmodel.BombStatusArmed, false, dbmodel.BombStatusArmed, true},
+		{"paused game delays the chain", dbmodel.GameStatusPaused, "classic", dbmodel.BombStatusArmed, true, dbmodel.BombStatusArmed, true},
+		{"burnt mine expires", dbmodel.GameStatusRunning, "mine", dbmodel.BombStatusArmed, false, dbmodel.BombStatusExpired, false},
+		{"chained mine explodes", dbmodel.GameStatusRunning, "mine", dbmodel.BombStatusArmed, true, dbmodel.BombStatusDetonated, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestScheduler(t)
+			game := createGame(t, db, tt.gameStatus)
+			bomb := createBomb(t, db, game, game.Teams[0].IDTeam, tt.typeBomb, center)
+			if err := db.Model(bomb).Update("status", tt.bombStatus).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			f := fuse{idBomb: bomb.BombID, at: time.Now()}
+			if tt.chained {
+				f.trigger = &dbmodel.DetonationEntry{IDBomb: 1000, IDChain: 1000}
+				s.reached[bomb.BombID] = true
+				s.chains[1000] = &chainState{size: 2, pending: 1}
+			}
+			s.fire(f)
+
+			if got := bombStatus(t, db, bomb.BombID); got != tt.want {
+				t.Errorf("bomb status = %s, want %s", got, tt.want)
+			}
+			if tt.wantQueued {
+				if s.queue.Len() != 1 || s.queue[0].trigger != f.trigger || s.queue[0].at.Before(time.Now().Add(pausedRetry-time.Second)) {
+					t.Errorf("queue = %v, want the fuse back %s later", s.queue, pausedRetry)
+				}
+			} else if s.queue.Len() != 0 {
+				t.Errorf("queue = %v, want it empty", s.queue)
+			}
+
+			// A chain only lets go of its bombs once they have fired for good
+			if tt.chained && s.reached[bomb.BombID] != tt.wantQueued {
+				t.Errorf("bomb reached by the chain = %v, want %v", s.reached[bomb.BombID], tt.wantQueued)
+			}
+			if _, ok := s.chains[1000]; tt.chained && ok != tt.wantQueued {
+				t.Errorf("chain still spreading = %v, want %v", ok, tt.wantQueued)
+			}
+		})
+	}
+}
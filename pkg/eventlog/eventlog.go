@@ -0,0 +1,122 @@
+// Package eventlog appends what happens during the games to their event log.
+//
+// Recording an event never fails the action behind it, failures are only logged.
+package eventlog
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+)
+
+type Log struct {
+	events dbmodel.GameEventRepository
+}
+
+func New(events dbmodel.GameEventRepository) *Log {
+	return &Log{events: events}
+}
+
+// Append an event to the log of the game, actor is nil for events of the server
+func (l *Log) Record(idGame uuid.UUID, kind dbmodel.GameEventType, actor *uuid.UUID, at time.Time, data map[string]interface{}) {
+	entry := &dbmodel.GameEventEntry{IDGame: idGame, At: at, Type: kind, IDActor: actor, Data: data}
+	if err := l.events.Append(entry); err != nil {
+		log.Printf("Failed to record %s event of game %s: %s\n", kind, idGame, err.Error())
+	}
+}
+
+func (l *Log) BombPlaced(bomb *dbmodel.BombEntry, actor uuid.UUID) {
+	l.Record(bomb.IDGame, dbmodel.EventBombPlaced, &actor, bomb.PlacedAt, bombData(bomb))
+}
+
+func (l *Log) BombMoved(bomb *dbmodel.BombEntry, from dbmodel.BombEntry, actor *uuid.UUID) {
+	data := bombData(bomb)
+	data["from_lat"] = from.Lat
+	data["from_long"] = from.Long
+	data["from_type_bomb"] = from.TypeBomb
+	l.Record(bomb.IDGame, dbmodel.EventBombMoved, actor, time.Now(), data)
+}
+
+func (l *Log) BombRemoved(bomb *dbmodel.BombEntry, actor *uuid.UUID) {
+	l.Record(bomb.IDGame, dbmodel.EventBombRemoved, actor, time.Now(), bombData(bomb))
+}
+
+func (l *Log) BombDetonated(bomb *dbmodel.BombEntry, detonation *dbmodel.DetonationEntry) {
+	data := bombData(bomb)
+	data["radius"] = detonation.Radius
+	data["damage"] = detonation.Damage
+	data["points"] = detonation.Points
+	data["hits"] = detonation.Hits
+	if detonation.IDTrigger != nil {
+		data["triggered_by"] = *detonation.IDTrigger
+		data["id_chain"] = detonation.IDChain
+		data["hop"] = detonation.Hop
+	}
+	if detonation.TrippedBy != nil {
+		data["tripped_by"] = *detonation.TrippedBy
+	}
+	l.Record(bomb.IDGame, dbmodel.EventBombDetonated, nil, detonation.DetonatedAt, data)
+}
+
+func (l *Log) BombDefused(bomb *dbmodel.BombEntry, defusal *dbmodel.DefusalEntry) {
+	data := bombData(bomb)
+	data["defused_by"] = defusal.IDUser
+	data["defuser_team"] = defusal.IDTeam
+	data["started_at"] = defusal.StartedAt
+	l.Record(bomb.IDGame, dbmodel.EventBombDefused, &defusal.IDUser, *defusal.ConfirmedAt, data)
+}
+
+// Points won or lost by a team, and why
+func (l *Log) ScoreChanged(score *dbmodel.ScoreEventEntry) {
+	data := map[string]interface{}{
+		"id_team": score.IDTeam,
+		"points":  score.Points,
+		"reason":  score.Reason,
+	}
+	if score.IDBomb != nil {
+		data["bomb_id"] = *score.IDBomb
+	}
+	if score.Objective != "" {
+		data["objective"] = score.Objective
+	}
+	l.Record(score.IDGame, dbmodel.EventScoreChanged, score.IDUser, score.At, data)
+}
+
+func (l *Log) PlayerJoined(idGame, idTeam uuid.UUID, user *dbmodel.UserEntry, party string) {
+	l.Record(idGame, dbmodel.EventPlayerJoined, &user.IDUser, time.Now(), map[string]interface{}{
+		"id_user":  user.IDUser,
+		"username": user.UserName,
+		"id_team":  idTeam,
+		"party":    party,
+	})
+}
+
+// Team of every player after a rebalance
+func (l *Log) TeamsBalanced(idGame uuid.UUID, assignment map[uuid.UUID]uuid.UUID, actor uuid.UUID) {
+	teams := map[string]interface{}{}
+	for idUser, idTeam := range assignment {
+		teams[idUser.String()] = idTeam
+	}
+	l.Record(idGame, dbmodel.EventTeamsBalanced, &actor, time.Now(), map[string]interface{}{"teams": teams})
+}
+
+func (l *Log) StatusChanged(idGame uuid.UUID, from, to dbmodel.GameStatus, actor *uuid.UUID) {
+	l.Record(idGame, dbmodel.EventStatusChanged, actor, time.Now(), map[string]interface{}{
+		"from": from,
+		"to":   to,
+	})
+}
+
+func bombData(bomb *dbmodel.BombEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"bomb_id":   bomb.BombID,
+		"id_user":   bomb.IdUser,
+		"id_team":   bomb.IDTeam,
+		"type_bomb": bomb.TypeBomb,
+		"lat":       bomb.Lat,
+		"long":      bomb.Long,
+	}
+}
@@ -0,0 +1,91 @@
+// Package fog hides the armed bombs of the other teams from the players who are too far to see them.
+//
+// Players always see the bombs of their team and the bombs that are not armed anymore. The armed bombs of
+// the other teams only show within the reveal radius of the game around the last position of the player.
+// Admins see everything.
+package fog
+
+import (
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+// What a user sees of the bombs, only meant to serve one request since positions move on
+type Viewer struct {
+	user      *dbmodel.UserEntry
+	games     dbmodel.GameRepository
+	positions dbmodel.PositionRepository
+
+	sight map[uuid.UUID]*geo.Circle // Area seen in each game, nil without position in it
+}
+
+func New(user *dbmodel.UserEntry, games dbmodel.GameRepository, positions dbmodel.PositionRepository) *Viewer {
+	return &Viewer{
+		user:      user,
+		games:     games,
+		positions: positions,
+		sight:     map[uuid.UUID]*geo.Circle{},
+	}
+}
+
+func (v *Viewer) Sees(bomb *dbmodel.BombEntry) bool {
+	if v.user.Role == dbmodel.UserRoleAdmin || bomb.Status != dbmodel.BombStatusArmed || v.teammate(bomb.IdUser, bomb.IDTeam) {
+		return true
+	}
+	sight := v.sightOf(bomb.IDGame)
+	return sight != nil && sight.Contains(bomb.Point())
+}
+
+// Bombs the viewer sees, in the same order
+func (v *Viewer) Filter(bombs []*dbmodel.BombEntry) []*dbmodel.BombEntry {
+	seen := []*dbmodel.BombEntry{}
+	for _, bomb := range bombs {
+		if v.Sees(bomb) {
+			seen = append(seen, bomb)
+		}
+	}
+	return seen
+}
+
+// Events placing, moving and removing a bomb show when the viewer sees the bomb. Once the bomb is removed,
+// only its team and the admins see them. The other events, detonations and defusals included, show to everyone
+func (v *Viewer) SeesEvent(event *dbmodel.GameEventEntry, bombs map[int]*dbmodel.BombEntry) bool {
+	switch event.Type {
+	case dbmodel.EventBombPlaced, dbmodel.EventBombMoved, dbmodel.EventBombRemoved:
+	default:
+		return true
+	}
+	if v.user.Role == dbmodel.UserRoleAdmin || event.IDActor != nil && *event.IDActor == v.user.IDUser {
+		return true
+	}
+
+	// Numbers are decoded from the JSON column as floats
+	idBomb, _ := event.Data["bomb_id"].(float64)
+	if bomb, ok := bombs[int(idBomb)]; ok {
+		return v.Sees(bomb)
+	}
+	idTeam, _ := event.Data["id_team"].(string)
+	return v.user.IDTeam != nil && idTeam == v.user.IDTeam.String()
+}
+
+func (v *Viewer) teammate(idUser, idTeam uuid.UUID) bool {
+	return idUser == v.user.IDUser || v.user.IDTeam != nil && idTeam == *v.user.IDTeam
+}
+
+func (v *Viewer) sightOf(idGame uuid.UUID) *geo.Circle {
+	if sight, ok := v.sight[idGame]; ok {
+		return sight
+	}
+
+	var sight *geo.Circle
+	game, err := v.games.FindById(idGame)
+	if err == nil {
+		if position, err := v.positions.FindLast(idGame, v.user.IDUser); err == nil {
+			sight = &geo.Circle{Center: geo.NewPoint(position.Lat, position.Long), Radius: game.Ruleset.RevealRange()}
+		}
+	}
+	v.sight[idGame] = sight
+	return sight
+}
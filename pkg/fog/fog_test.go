@@ -0,0 +1,102 @@
+package fog
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+// Only the lookups the viewer makes are implemented
+type fakeGames struct {
+	dbmodel.GameRepository
+	games map[uuid.UUID]*dbmodel.GameEntry
+}
+
+func (f fakeGames) FindById(id uuid.UUID) (*dbmodel.GameEntry, error) {
+	if game, ok := f.games[id]; ok {
+		return game, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakePositions struct {
+	dbmodel.PositionRepository
+	last map[uuid.UUID]*dbmodel.PositionEntry // By user
+}
+
+func (f fakePositions) FindLast(idGame, idUser uuid.UUID) (*dbmodel.PositionEntry, error) {
+	if position, ok := f.last[idUser]; ok && position.IDGame == idGame {
+		return position, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestSees(t *testing.T) {
+	center := geo.Point{Lat: 48.85, Long: 2.35}
+	red, blue := uuid.New(), uuid.New()
+	game := &dbmodel.GameEntry{IDGame: uuid.New(), Ruleset: rules.Ruleset{RevealRadius: 100}}
+	narrow := &dbmodel.GameEntry{IDGame: uuid.New(), Ruleset: rules.Ruleset{RevealRadius: 1}}
+
+	player := &dbmodel.UserEntry{IDUser: uuid.New(), IDTeam: &red, Role: dbmodel.UserRolePlayer}
+	lost := &dbmodel.UserEntry{IDUser: uuid.New(), IDTeam: &red, Role: dbmodel.UserRolePlayer}
+	admin := &dbmodel.UserEntry{IDUser: uuid.New(), Role: dbmodel.UserRoleAdmin}
+	opponent := uuid.New()
+
+	games := fakeGames{games: map[uuid.UUID]*dbmodel.GameEntry{game.IDGame: game, narrow.IDGame: narrow}}
+	positions := fakePositions{last: map[uuid.UUID]*dbmodel.PositionEntry{
+		player.IDUser: {IDGame: game.IDGame, IDUser: player.IDUser, Lat: float32(center.Lat), Long: float32(center.Long)},
+	}}
+
+	bomb := func(idGame uuid.UUID, idUser, idTeam uuid.UUID, status dbmodel.BombStatus, distance float64) *dbmodel.BombEntry {
+		p := geo.Destination(center, 0, distance)
+		return &dbmodel.BombEntry{IDGame: idGame, IdUser: idUser, IDTeam: idTeam, Status: status, Lat: float32(p.Lat), Long: float32(p.Long)}
+	}
+
+	tests := []struct {
+		name   string
+		viewer *dbmodel.UserEntry
+		bomb   *dbmodel.BombEntry
+		want   bool
+	}{
+		{"own bomb far away", player, bomb(game.IDGame, player.IDUser, red, dbmodel.BombStatusArmed, 5000), true},
+		{"teammate bomb far away", player, bomb(game.IDGame, uuid.New(), red, dbmodel.BombStatusArmed, 5000), true},
+		{"opponent bomb in sight", player, bomb(game.IDGame, opponent, blue, dbmodel.BombStatusArmed, 80), true},
+		{"opponent bomb out of sight", player, bomb(game.IDGame, opponent, blue, dbmodel.BombStatusArmed, 150), false},
+		{"opponent bomb detonated far away", player, bomb(game.IDGame, opponent, blue, dbmodel.BombStatusDetonated, 5000), true},
+		{"opponent bomb in another game", player, bomb(narrow.IDGame, opponent, blue, dbmodel.BombStatusArmed, 0), false},
+		{"opponent bomb without position", lost, bomb(game.IDGame, opponent, blue, dbmodel.BombStatusArmed, 0), false},
+		{"opponent bomb in an unknown game", player, bomb(uuid.New(), opponent, blue, dbmodel.BombStatusArmed, 0), false},
+		{"admin sees everything", admin, bomb(game.IDGame, opponent, blue, dbmodel.BombStatusArmed, 5000), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.viewer, games, positions).Sees(tt.bomb); got != tt.want {
+				t.Errorf("Sees() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeesDefuseRange(t *testing.T) {
+	// The reveal radius never hides a bomb close enough to be defused
+	center := geo.Point{Lat: 48.85, Long: 2.35}
+	game := &dbmodel.GameEntry{IDGame: uuid.New(), Ruleset: rules.Ruleset{RevealRadius: 1}}
+	team := uuid.New()
+	viewer := &dbmodel.UserEntry{IDUser: uuid.New(), IDTeam: &team}
+
+	games := fakeGames{games: map[uuid.UUID]*dbmodel.GameEntry{game.IDGame: game}}
+	positions := fakePositions{last: map[uuid.UUID]*dbmodel.PositionEntry{
+		viewer.IDUser: {IDGame: game.IDGame, IDUser: viewer.IDUser, Lat: float32(center.Lat), Long: float32(center.Long)},
+	}}
+
+	p := geo.Destination(center, 90, rules.DefaultDefuseDistance-2)
+	bomb := &dbmodel.BombEntry{IDGame: game.IDGame, IdUser: uuid.New(), IDTeam: uuid.New(), Status: dbmodel.BombStatusArmed, Lat: float32(p.Lat), Long: float32(p.Long)}
+	if !New(viewer, games, positions).Sees(bomb) {
+		t.Error("Sees() hid a bomb within the defuse range")
+	}
+}
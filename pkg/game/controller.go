@@ -2,10 +2,14 @@ package game
 
 import (
 	"net/http"
+	"time"
 
 	"bombparty.com/bombparty-api/config"
 	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/geo"
 	"bombparty.com/bombparty-api/pkg/model"
+	"bombparty.com/bombparty-api/pkg/rules"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
@@ -33,6 +37,13 @@ func New(configuration *config.Config) *GameConfig {
 // @Router       /api/v1/game [post]
 func (config *GameConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
 	// Get the request
 	req := &model.GameRequest{}
 	if err := render.Bind(r, req); err != nil {
@@ -52,7 +63,34 @@ func (config *GameConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 		CenterLongitude: *req.CenterLongitude,
 		Size:            *req.Size,
 		StartingDate:    *req.StartingDate,
-		EndingDate:      *req.EndingDate}
+		EndingDate:      *req.EndingDate,
+		Boundary:        req.Boundary,
+		ExclusionZones:  req.ExclusionZones,
+		ZoneSchedule:    req.ZoneSchedule,
+		IDHost:          user.IDUser}
+	if req.Mode != nil {
+		gameEntry.Mode = dbmodel.GameMode(*req.Mode)
+	}
+	if req.BalanceStrategy != nil {
+		gameEntry.BalanceStrategy = *req.BalanceStrategy
+	}
+	if req.Visibility != nil {
+		gameEntry.Visibility = dbmodel.GameVisibility(*req.Visibility)
+	}
+	if req.Ruleset != nil {
+		gameEntry.Ruleset = *req.Ruleset
+	}
+
+	if err := config.checkRuleset(req.Ruleset); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+	if err := prepareZoneSchedule(gameEntry); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
 
 	// Request the DB to Create the informations
 	entries, err := config.GameRepository.Create(gameEntry)
@@ -62,15 +100,7 @@ func (config *GameConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.GameResponse{
-		CenterLatitude:  entries.CenterLatitude,
-		CenterLongitude: entries.CenterLongitude,
-		Size:            entries.Size,
-		StartingDate:    entries.StartingDate,
-		EndingDate:      entries.EndingDate,
-		Teams:           []*model.TeamResponse{}}
-
-	render.JSON(w, r, res)
+	render.JSON(w, r, convertToResponse(entries))
 }
 
 // GetByIdHandler godoc
@@ -108,32 +138,21 @@ func (config *GameConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Set up to a dedicated type for the response
-	var teams []*model.TeamResponse
-
-	for _, team := range entries.Teams {
-		teams = append(teams, &model.TeamResponse{
-			Score:  team.Score,
-			Name:   team.Name,
-			Color:  team.Color,
-			//IDGame: team.IDGame
-			})
+	// Private games don't exist for the users outside of them
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil || !entries.VisibleTo(user) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Game not found in the DB"})
+		return
 	}
 
-	res := &model.GameResponse{
-		CenterLatitude:  entries.CenterLatitude,
-		CenterLongitude: entries.CenterLongitude,
-		Size:            entries.Size,
-		StartingDate:    entries.StartingDate,
-		EndingDate:      entries.EndingDate,
-		Teams:           teams}
-
-	render.JSON(w, r, res)
+	// Set up to a dedicated type for the response
+	render.JSON(w, r, convertToResponse(entries))
 }
 
 // GetAlldHandler godoc
 // @Summary      Get all games
-// @Description  Retrieves a list of all games from the database
+// @Description  Retrieves the public games along with the games the user hosts or plays in
 // @Tags         games
 // @Produce      json
 // @Security     BearerAuth
@@ -142,7 +161,14 @@ func (config *GameConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request)
 // @Router       /api/v1/games [get]
 func (config *GameConfig) GetAlldHandler(w http.ResponseWriter, r *http.Request) {
 
-	entries, err := config.GameRepository.FindAll()
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	entries, err := config.GameRepository.FindListed(user)
 	if err != nil {
 		render.JSON(w, r, map[string]string{"Error": "Failed to Find Games"})
 		return
@@ -152,24 +178,7 @@ func (config *GameConfig) GetAlldHandler(w http.ResponseWriter, r *http.Request)
 	var res []*model.GameResponse
 
 	for _, game := range entries {
-		var teams []*model.TeamResponse
-		for _, team := range game.Teams {
-			teams = append(teams, &model.TeamResponse{
-				Score:  team.Score,
-				Name:   team.Name,
-				Color:  team.Color,
-				//IDGame: team.IDGame
-			})
-		}
-
-		res = append(res,
-			&model.GameResponse{
-				CenterLatitude:  game.CenterLatitude,
-				CenterLongitude: game.CenterLongitude,
-				Size:            game.Size,
-				StartingDate:    game.StartingDate,
-				EndingDate:      game.EndingDate,
-				Teams:           teams})
+		res = append(res, convertToResponse(game))
 	}
 
 	render.JSON(w, r, res)
@@ -186,23 +195,15 @@ func (config *GameConfig) GetAlldHandler(w http.ResponseWriter, r *http.Request)
 // @Security     BearerAuth
 // @Success      200    {object}  model.GameResponse
 // @Failure      400    {object}  map[string]string  "Invalid request payload"
+// @Failure      403    {object}  map[string]string  "Not the host of the game"
 // @Failure      404    {object}  map[string]string  "Game not found"
+// @Failure      409    {object}  map[string]string  "Game is finished or its ruleset is settled"
 // @Failure      500    {object}  map[string]string  "Failed to update game"
 // @Router       /api/v1/games/{id} [patch]
 func (config *GameConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 
-	// Get the id in the URL
-	idStr := chi.URLParam(r, "id")
-	if idStr == "" {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, map[string]string{"Error": "Missing Id"})
-		return
-	}
-
-	uuid, err := uuid.Parse(idStr)
-	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+	existingGame, ok := config.findHostedGame(w, r)
+	if !ok {
 		return
 	}
 
@@ -219,10 +220,17 @@ func (config *GameConfig) UpdateHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if the linked cat id existe
-	existingGame, err := config.GameRepository.FindById(uuid)
-	if err != nil {
-		render.JSON(w, r, map[string]string{"Error": "Game not found in the DB"})
+	// A finished game is an official result and cannot be edited anymore
+	if existingGame.IsFinished() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Game is finished and cannot be updated"})
+		return
+	}
+
+	// Changing the rules in the middle of a game would be unfair to the players
+	if req.Ruleset != nil && !existingGame.AcceptsRuleChanges() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Ruleset cannot change once the game has started"})
 		return
 	}
 
@@ -245,35 +253,48 @@ func (config *GameConfig) UpdateHandler(w http.ResponseWriter, r *http.Request)
 	if req.EndingDate != nil {
 		gameEntry.EndingDate = *req.EndingDate
 	}
+	if req.Mode != nil {
+		gameEntry.Mode = dbmodel.GameMode(*req.Mode)
+	}
+	if req.BalanceStrategy != nil {
+		gameEntry.BalanceStrategy = *req.BalanceStrategy
+	}
+	if req.Visibility != nil {
+		gameEntry.Visibility = dbmodel.GameVisibility(*req.Visibility)
+	}
+	if req.Boundary != nil {
+		gameEntry.Boundary = req.Boundary
+	}
+	if req.ExclusionZones != nil {
+		gameEntry.ExclusionZones = req.ExclusionZones
+	}
+	if req.ZoneSchedule != nil {
+		gameEntry.ZoneSchedule = req.ZoneSchedule
+	}
+	if req.Ruleset != nil {
+		gameEntry.Ruleset = *req.Ruleset
+	}
+
+	if err := config.checkRuleset(req.Ruleset); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+	if err := prepareZoneSchedule(gameEntry); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
 
 	// Request the DB to Update the informations
-	entries, err := config.GameRepository.Update(gameEntry, uuid)
+	entries, err := config.GameRepository.Update(gameEntry, gameEntry.IDGame)
 	if err != nil {
 		render.JSON(w, r, map[string]string{"Error": "Failed to Update Game"})
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	var teams []*model.TeamResponse
-	for _, team := range entries.Teams {
-		teams = append(teams,
-			&model.TeamResponse{
-				Score:  team.Score,
-				Name:   team.Name,
-				Color:  team.Color,
-				//IDGame: team.IDGame,
-			})
-	}
-
-	res := &model.GameResponse{
-		CenterLatitude:  entries.CenterLatitude,
-		CenterLongitude: entries.CenterLongitude,
-		Size:            entries.Size,
-		StartingDate:    entries.StartingDate,
-		EndingDate:      entries.EndingDate,
-		Teams:           teams}
-
-	render.JSON(w, r, res)
+	render.JSON(w, r, convertToResponse(entries))
 }
 
 // DeleteHandler godoc
@@ -284,28 +305,20 @@ func (config *GameConfig) UpdateHandler(w http.ResponseWriter, r *http.Request)
 // @Param        id   path      string  true  "Game ID"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]string  "Game deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
 // @Failure      404  {object}  map[string]string  "Game not found"
 // @Failure      500  {object}  map[string]string  "Failed to delete game"
 // @Router       /api/v1/games/{id} [delete]
 func (config *GameConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
-	// Get the id in the URL
-	idStr := chi.URLParam(r, "id")
-	if idStr == "" {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, map[string]string{"Error": "Missing Id"})
-		return
-	}
-
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+	game, ok := config.findHostedGame(w, r)
+	if !ok {
 		return
 	}
 
 	// Request the DB to Delete the informations
-	errDelete := config.GameRepository.DeleteById(id)
+	errDelete := config.GameRepository.DeleteById(game.IDGame)
 	if errDelete != nil {
 		render.JSON(w, r, map[string]string{"Error": "Failed to Delete Game"})
 		return
@@ -313,3 +326,95 @@ func (config *GameConfig) DeleteHandler(w http.ResponseWriter, r *http.Request)
 
 	render.JSON(w, r, map[string]string{"message": "Game deleted successfully"})
 }
+
+// Fetch the game of the id in the URL, private games are not found by the users outside of them
+func (config *GameConfig) findGame(w http.ResponseWriter, r *http.Request) (*dbmodel.GameEntry, bool) {
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+		return nil, false
+	}
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return nil, false
+	}
+
+	game, err := config.GameRepository.FindById(id)
+	if err != nil || !game.VisibleTo(user) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Game not found in the DB"})
+		return nil, false
+	}
+
+	return game, true
+}
+
+// Check the bomb types named by the ruleset are registered
+func (config *GameConfig) checkRuleset(ruleset *rules.Ruleset) error {
+	if ruleset == nil {
+		return nil
+	}
+	types, err := config.BombTypeRepository.Types()
+	if err != nil {
+		return err
+	}
+	return ruleset.CheckTypes(types)
+}
+
+// Check the zone schedule against the game circle and fix its final center
+func prepareZoneSchedule(game *dbmodel.GameEntry) error {
+	if game.ZoneSchedule == nil {
+		return nil
+	}
+	if err := game.ZoneSchedule.Validate(game.Circle()); err != nil {
+		return err
+	}
+	game.ZoneSchedule.ResolveCenter(game.Circle())
+	return nil
+}
+
+func convertToResponse(game *dbmodel.GameEntry) *model.GameResponse {
+	teams := []*model.TeamResponse{}
+	for _, team := range game.Teams {
+		teams = append(teams, &model.TeamResponse{
+			Score:  team.Score,
+			Name:   team.Name,
+			Color:  team.Color,
+			IDGame: team.IDGame,
+		})
+	}
+
+	return &model.GameResponse{
+		IDGame:          game.IDGame,
+		Status:          string(game.Status),
+		Mode:            string(game.Mode),
+		JoinCode:        game.JoinCode,
+		IDHost:          game.IDHost,
+		BalanceStrategy: game.BalanceStrategy,
+		Visibility:      string(game.Visibility),
+		CenterLatitude:  game.CenterLatitude,
+		CenterLongitude: game.CenterLongitude,
+		Size:            game.Size,
+		StartingDate:    game.StartingDate,
+		EndingDate:      game.EndingDate,
+		Boundary:        game.Boundary,
+		ExclusionZones:  game.ExclusionZones,
+		ZoneSchedule:    publicZoneSchedule(game, time.Now()),
+		Ruleset:         game.Ruleset,
+		Teams:           teams}
+}
+
+// Schedule of the zone without its final center until the last phase is announced, GET zone serves it then
+func publicZoneSchedule(game *dbmodel.GameEntry, now time.Time) *geo.ZoneSchedule {
+	if game.ZoneSchedule == nil || !now.Before(game.ZoneSchedule.FinalAnnouncedAt(game.StartingDate)) {
+		return game.ZoneSchedule
+	}
+	schedule := *game.ZoneSchedule
+	schedule.FinalCenter = nil
+	return &schedule
+}
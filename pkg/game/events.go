@@ -0,0 +1,86 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/fog"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/render"
+)
+
+// GetEventsHandler godoc
+// @Summary      Get the event log of a game
+// @Description  Retrieves what happened during the game, oldest first, to replay it. Events of the bombs hidden by the fog of war are left out
+// @Tags         games
+// @Produce      json
+// @Param        id    path      string  true   "Game ID"
+// @Param        from  query     string  false  "RFC 3339 timestamp, defaults to the first event"
+// @Param        to    query     string  false  "RFC 3339 timestamp, defaults to now"
+// @Security     BearerAuth
+// @Success      200  {array}   model.GameEventResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id or timestamp"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to find events"
+// @Router       /api/v1/games/{id}/events [get]
+func (config *GameConfig) GetEventsHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseTimeRange(w, r, time.Time{}, time.Now())
+	if !ok {
+		return
+	}
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	entries, err := config.EventRepository.FindByGame(game.IDGame, from, to)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find events"})
+		return
+	}
+
+	// Bombs as they are now decide which of their events show through the fog of war
+	bombs, err := config.BombRepository.FindAllByGameId(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find events"})
+		return
+	}
+	byId := map[int]*dbmodel.BombEntry{}
+	for _, bomb := range bombs {
+		byId[bomb.BombID] = bomb
+	}
+	viewer := fog.New(user, config.GameRepository, config.PositionRepository)
+
+	res := []*model.GameEventResponse{}
+	for _, entry := range entries {
+		if viewer.SeesEvent(entry, byId) {
+			res = append(res, convertToEventResponse(entry))
+		}
+	}
+
+	render.JSON(w, r, res)
+}
+
+func convertToEventResponse(entry *dbmodel.GameEventEntry) *model.GameEventResponse {
+	return &model.GameEventResponse{
+		ID:      entry.IDEvent,
+		IDGame:  entry.IDGame,
+		Type:    string(entry.Type),
+		IDActor: entry.IDActor,
+		At:      entry.At,
+		Data:    entry.Data,
+	}
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"github.com/go-chi/render"
+)
+
+// ScheduleHandler godoc
+// @Summary      Schedule a game
+// @Description  Moves a draft game to the scheduled status
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Transition not allowed"
+// @Router       /api/v1/games/{id}/schedule [post]
+func (config *GameConfig) ScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	config.transition(w, r, "schedule")
+}
+
+// StartHandler godoc
+// @Summary      Start a game
+// @Description  Moves a draft or scheduled game to the running status
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Transition not allowed"
+// @Router       /api/v1/games/{id}/start [post]
+func (config *GameConfig) StartHandler(w http.ResponseWriter, r *http.Request) {
+	config.transition(w, r, "start")
+}
+
+// PauseHandler godoc
+// @Summary      Pause a game
+// @Description  Moves a running game to the paused status
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Transition not allowed"
+// @Router       /api/v1/games/{id}/pause [post]
+func (config *GameConfig) PauseHandler(w http.ResponseWriter, r *http.Request) {
+	config.transition(w, r, "pause")
+}
+
+// ResumeHandler godoc
+// @Summary      Resume a game
+// @Description  Moves a paused game back to the running status
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Transition not allowed"
+// @Router       /api/v1/games/{id}/resume [post]
+func (config *GameConfig) ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	config.transition(w, r, "resume")
+}
+
+// FinishHandler godoc
+// @Summary      Finish a game
+// @Description  Moves a running or paused game to the finished status
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Transition not allowed"
+// @Router       /api/v1/games/{id}/finish [post]
+func (config *GameConfig) FinishHandler(w http.ResponseWriter, r *http.Request) {
+	config.transition(w, r, "finish")
+}
+
+// Apply a lifecycle action to the game given in the URL
+func (config *GameConfig) transition(w http.ResponseWriter, r *http.Request, action string) {
+
+	// Only the host and the admins drive the game
+	game, ok := config.findHostedGame(w, r)
+	if !ok {
+		return
+	}
+
+	// Check that the action fits the current status
+	status, err := game.Transition(action)
+	if err != nil {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Cannot " + action + " a " + string(game.Status) + " game"})
+		return
+	}
+
+	actor := authentication.CurrentUserId(r, config.UserRepository)
+	if status == dbmodel.GameStatusFinished {
+		// Finishing also freezes the result of the game
+		_, err = config.Finisher.Finish(game, actor, time.Now())
+	} else if err = config.GameRepository.UpdateStatus(game.IDGame, game.Status, status); err == nil {
+		config.Events.StatusChanged(game.IDGame, game.Status, status, actor)
+	}
+	if err != nil {
+		if errors.Is(err, dbmodel.ErrInvalidTransition) {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"Error": "Game status changed in the meantime, retry"})
+			return
+		}
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Update Game status"})
+		return
+	}
+	game.Status = status
+
+	render.JSON(w, r, convertToResponse(game))
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+)
+
+// Private game hosted by alice, who plays in red against bob in blue. Eve plays in no game and root is an admin
+type gameFixture struct {
+	config *config.Config
+	db     *gorm.DB
+	game   *dbmodel.GameEntry
+	users  map[string]*dbmodel.UserEntry
+	tokens map[string]string
+}
+
+func newGameFixture(t *testing.T, status dbmodel.GameStatus) *gameFixture {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	configuration, err := config.NewWithDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.JwtKey = "test"
+
+	f := &gameFixture{config: configuration, db: db, users: map[string]*dbmodel.UserEntry{}, tokens: map[string]string{}}
+	alice := uuid.New()
+	f.game = &dbmodel.GameEntry{
+		CenterLatitude:  48.85,
+		CenterLongitude: 2.35,
+		Size:            500,
+		StartingDate:    time.Now().Add(-time.Hour),
+		EndingDate:      time.Now().Add(time.Hour),
+		Status:          status,
+		Visibility:      dbmodel.GameVisibilityPrivate,
+		IDHost:          alice,
+		Teams:           []dbmodel.TeamEntry{{Name: "red"}, {Name: "blue"}},
+	}
+	f.create(t, f.game)
+
+	f.addUser(t, "alice", alice, &f.game.Teams[0].IDTeam, dbmodel.UserRolePlayer)
+	f.addUser(t, "bob", uuid.New(), &f.game.Teams[1].IDTeam, dbmodel.UserRolePlayer)
+	f.addUser(t, "eve", uuid.New(), nil, dbmodel.UserRolePlayer)
+	f.addUser(t, "root", uuid.New(), nil, dbmodel.UserRoleAdmin)
+	return f
+}
+
+func (f *gameFixture) addUser(t *testing.T, name string, id uuid.UUID, team *uuid.UUID, role dbmodel.UserRole) {
+	t.Helper()
+	user := &dbmodel.UserEntry{IDUser: id, UserName: name, Email: name + "@bombparty.com", IDTeam: team, Role: role}
+	f.create(t, user)
+	token, err := authentication.GenerateToken(f.config.JwtKey, user.Email, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.users[name], f.tokens[name] = user, token
+}
+
+func (f *gameFixture) create(t *testing.T, value any) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Send the request of the user to the game routes, body is encoded as JSON unless nil
+func (f *gameFixture) do(t *testing.T, name, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := httptest.NewRequest(method, path, &payload)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+f.tokens[name])
+	w := httptest.NewRecorder()
+	Routes(f.config).ServeHTTP(w, r)
+	return w
+}
+
+func (f *gameFixture) status(t *testing.T) dbmodel.GameStatus {
+	t.Helper()
+	game, err := f.config.GameRepository.FindById(f.game.IDGame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game.Status
+}
+
+func TestLifecycleHandlers(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   dbmodel.GameStatus
+		user   string
+		action string
+		want   int
+		to     dbmodel.GameStatus
+	}{
+		{"host schedules", dbmodel.GameStatusDraft, "alice", "schedule", http.StatusOK, dbmodel.GameStatusScheduled},
+		{"host starts", dbmodel.GameStatusScheduled, "alice", "start", http.StatusOK, dbmodel.GameStatusRunning},
+		{"admin pauses", dbmodel.GameStatusRunning, "root", "pause", http.StatusOK, dbmodel.GameStatusPaused},
+		{"host resumes", dbmodel.GameStatusPaused, "alice", "resume", http.StatusOK, dbmodel.GameStatusRunning},
+		{"host finishes", dbmodel.GameStatusPaused, "alice", "finish", http.StatusOK, dbmodel.GameStatusFinished},
+		{"player starts", dbmodel.GameStatusDraft, "bob", "start", http.StatusForbidden, dbmodel.GameStatusDraft},
+		{"player finishes", dbmodel.GameStatusRunning, "bob", "finish", http.StatusForbidden, dbmodel.GameStatusRunning},
+		{"outsider of the private game", dbmodel.GameStatusRunning, "eve", "pause", http.StatusNotFound, dbmodel.GameStatusRunning},
+		{"transition not allowed", dbmodel.GameStatusRunning, "alice", "resume", http.StatusConflict, dbmodel.GameStatusRunning},
+		{"finished for good", dbmodel.GameStatusFinished, "root", "start", http.StatusConflict, dbmodel.GameStatusFinished},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGameFixture(t, tt.from)
+
+			w := f.do(t, tt.user, http.MethodPost, "/"+f.game.IDGame.String()+"/"+tt.action, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if got := f.status(t); got != tt.to {
+				t.Errorf("game %s, want %s", got, tt.to)
+			}
+		})
+	}
+}
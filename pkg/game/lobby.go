@@ -0,0 +1,336 @@
+package game
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/balance"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+// JoinHandler godoc
+// @Summary      Join a game with its code
+// @Description  Puts the authenticated user into a team of the game, the team with the fewest players unless one is given.
+// @Description  Teams can only be switched until the game starts.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Param        join  body      model.JoinRequest  true  "Join code and optional team"
+// @Security     BearerAuth
+// @Success      200  {object}  model.LobbyResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload or team"
+// @Failure      404  {object}  map[string]string  "No game with this code"
+// @Failure      409  {object}  map[string]string  "Game finished, without team, or user busy in another game"
+// @Failure      500  {object}  map[string]string  "Failed to join the game"
+// @Router       /api/v1/games/join [post]
+func (config *GameConfig) JoinHandler(w http.ResponseWriter, r *http.Request) {
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	req := &model.JoinRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Join request payload. " + err.Error()})
+		return
+	}
+
+	game, err := config.GameRepository.FindByJoinCode(req.Code)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "No game with this join code"})
+		return
+	}
+
+	if game.IsFinished() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Game is finished and cannot be joined"})
+		return
+	}
+	if len(game.Teams) == 0 {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Game has no team to join yet"})
+		return
+	}
+
+	// Players cannot leave a game in progress, nor switch team once it started
+	current, err := config.GameRepository.FindByUserId(user.IDUser)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find the game of the user"})
+		return
+	}
+	inGame := err == nil && current.IDGame == game.IDGame
+	if err == nil && !inGame && current.AcceptsScoreChanges() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "User is still playing another game"})
+		return
+	}
+
+	players, err := config.UserRepository.FindByGame(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find the players of the game"})
+		return
+	}
+
+	idTeam, err := chooseTeam(game, players, user, req)
+	if errors.Is(err, errTeamNotInGame) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Team is not part of this game"})
+		return
+	}
+	if err != nil {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "The party of the user already plays in another team"})
+		return
+	}
+
+	alreadyIn := user.IDTeam != nil && *user.IDTeam == idTeam
+	if !alreadyIn && inGame && game.AcceptsScoreChanges() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Teams cannot be switched once the game started"})
+		return
+	}
+
+	if err := config.UserRepository.JoinTeam(user.IDUser, &idTeam, req.Party); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Join the game"})
+		return
+	}
+	config.Events.PlayerJoined(game.IDGame, idTeam, user, req.Party)
+
+	config.renderLobby(w, r, game)
+}
+
+// GetLobbyHandler godoc
+// @Summary      Get the lobby of a game
+// @Description  Lists the players of each team of the game
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.LobbyResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to find the players"
+// @Router       /api/v1/games/{id}/lobby [get]
+func (config *GameConfig) GetLobbyHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	config.renderLobby(w, r, game)
+}
+
+// RegenerateJoinCodeHandler godoc
+// @Summary      Regenerate the join code of a game
+// @Description  Replaces the join code of the game, the previous code stops working. Only the host or an admin can regenerate it
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.JoinCodeResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to regenerate the code"
+// @Router       /api/v1/games/{id}/join-code [post]
+func (config *GameConfig) RegenerateJoinCodeHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findHostedGame(w, r)
+	if !ok {
+		return
+	}
+
+	code, err := config.GameRepository.RegenerateJoinCode(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Regenerate the join code"})
+		return
+	}
+
+	render.JSON(w, r, model.JoinCodeResponse{JoinCode: code})
+}
+
+// Fetch the game of the URL, only if the authenticated user hosts it or is an admin
+func (config *GameConfig) findHostedGame(w http.ResponseWriter, r *http.Request) (*dbmodel.GameEntry, bool) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil || !game.ManagedBy(user) {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"Error": "Only the host of the game can do this"})
+		return nil, false
+	}
+
+	return game, true
+}
+
+// RebalanceHandler godoc
+// @Summary      Rebalance the teams of a game
+// @Description  Reshuffles every player of the game with its balance strategy, keeping parties together.
+// @Description  Only the host or an admin can rebalance, and only before the game starts
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.LobbyResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Game already started or without team"
+// @Failure      500  {object}  map[string]string  "Failed to rebalance the teams"
+// @Router       /api/v1/games/{id}/rebalance [post]
+func (config *GameConfig) RebalanceHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findHostedGame(w, r)
+	if !ok {
+		return
+	}
+
+	if game.Status != dbmodel.GameStatusDraft && game.Status != dbmodel.GameStatusScheduled {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Teams can only be rebalanced before the game starts"})
+		return
+	}
+	if len(game.Teams) == 0 {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Game has no team to balance"})
+		return
+	}
+
+	players, err := config.UserRepository.FindByGame(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find the players of the game"})
+		return
+	}
+
+	strategy := balance.StrategyOf(game.BalanceStrategy)
+	assignment := balance.Rebalance(strategy, teamIds(game), toBalancePlayers(players))
+	if err := config.UserRepository.AssignTeams(assignment); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Rebalance the teams"})
+		return
+	}
+	// Only the host and the admins get past findHostedGame, the host stands in if the user is gone since
+	actor := game.IDHost
+	if idUser := authentication.CurrentUserId(r, config.UserRepository); idUser != nil {
+		actor = *idUser
+	}
+	config.Events.TeamsBalanced(game.IDGame, assignment, actor)
+
+	config.renderLobby(w, r, game)
+}
+
+var (
+	errTeamNotInGame = errors.New("team is not part of the game")
+	errPartySplit    = errors.New("party already plays in another team")
+)
+
+// Team the user joins: the requested one if it belongs to the game and keeps the party
+// together, the current one if the user stays in the game with the same party, the one
+// picked by the balance strategy of the game otherwise
+func chooseTeam(game *dbmodel.GameEntry, players []*dbmodel.UserEntry, user *dbmodel.UserEntry, req *model.JoinRequest) (uuid.UUID, error) {
+
+	teams := teamIds(game)
+	newcomer := balance.Player{ID: user.IDUser, Skill: user.Skill, Party: req.Party}
+	strategy := balance.StrategyOf(game.BalanceStrategy)
+
+	// Team where the party of the user already plays, if any
+	partyTeam := uuid.Nil
+	if req.Party != "" {
+		for _, player := range players {
+			if player.IDUser != user.IDUser && player.Party == req.Party {
+				partyTeam = *player.IDTeam
+			}
+		}
+	}
+
+	switch {
+	case req.IDTeam != nil:
+		if !slices.Contains(teams, *req.IDTeam) {
+			return uuid.Nil, errTeamNotInGame
+		}
+		if partyTeam != uuid.Nil && partyTeam != *req.IDTeam {
+			return uuid.Nil, errPartySplit
+		}
+		return *req.IDTeam, nil
+	case user.IDTeam != nil && slices.Contains(teams, *user.IDTeam) && user.Party == req.Party:
+		return *user.IDTeam, nil
+	default:
+		return balance.Assign(strategy, teams, toBalancePlayers(players), newcomer), nil
+	}
+}
+
+func teamIds(game *dbmodel.GameEntry) []uuid.UUID {
+	ids := make([]uuid.UUID, len(game.Teams))
+	for i, team := range game.Teams {
+		ids[i] = team.IDTeam
+	}
+	return ids
+}
+
+func toBalancePlayers(users []*dbmodel.UserEntry) []balance.Player {
+	players := make([]balance.Player, len(users))
+	for i, user := range users {
+		players[i] = balance.Player{ID: user.IDUser, Skill: user.Skill, Party: user.Party}
+		if user.IDTeam != nil {
+			players[i].Team = *user.IDTeam
+		}
+	}
+	return players
+}
+
+func (config *GameConfig) renderLobby(w http.ResponseWriter, r *http.Request, game *dbmodel.GameEntry) {
+
+	players, err := config.UserRepository.FindByGame(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find the players of the game"})
+		return
+	}
+
+	res := &model.LobbyResponse{
+		IDGame:       game.IDGame,
+		Status:       string(game.Status),
+		JoinCode:     game.JoinCode,
+		IDHost:       game.IDHost,
+		StartingDate: game.StartingDate,
+		Teams:        []model.LobbyTeam{},
+	}
+	for _, team := range game.Teams {
+		lobbyTeam := model.LobbyTeam{IDTeam: team.IDTeam, Name: team.Name, Color: team.Color, Players: []model.LobbyPlayer{}}
+		for _, player := range players {
+			if *player.IDTeam == team.IDTeam {
+				lobbyTeam.Players = append(lobbyTeam.Players, model.LobbyPlayer{
+					IDUser:   player.IDUser,
+					UserName: player.UserName,
+					Skill:    player.Skill,
+					Party:    player.Party,
+				})
+			}
+		}
+		res.Teams = append(res.Teams, lobbyTeam)
+	}
+
+	render.JSON(w, r, res)
+}
@@ -0,0 +1,267 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// ReportLocationHandler godoc
+// @Summary      Report the location of the player
+// @Description  Stores a GPS fix of the authenticated player in the game, setting off the mines of opponents it walks into
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                 true  "Game ID"
+// @Param        location  body      model.LocationRequest  true  "GPS fix"
+// @Security     BearerAuth
+// @Success      201  {object}  model.LocationResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload or timestamp more than a minute off"
+// @Failure      403  {object}  map[string]string  "Player is not part of the game"
+// @Failure      409  {object}  map[string]string  "Game is not running"
+// @Failure      500  {object}  map[string]string  "Failed to store location"
+// @Router       /api/v1/games/{id}/location [post]
+func (config *GameConfig) ReportLocationHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+		return
+	}
+
+	// Get the request
+	req := &model.LocationRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Location request payload. " + err.Error()})
+		return
+	}
+
+	user, game, ok := config.findMemberGame(w, r, id)
+	if !ok {
+		return
+	}
+
+	if game.Status != dbmodel.GameStatusRunning && game.Status != dbmodel.GameStatusPaused {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Locations are only recorded while the game is running"})
+		return
+	}
+
+	entry, err := config.PositionRepository.Create(&dbmodel.PositionEntry{
+		IDGame:     id,
+		IDUser:     user.IDUser,
+		Lat:        *req.Lat,
+		Long:       *req.Long,
+		Accuracy:   req.Accuracy,
+		RecordedAt: time.Now(),
+		ReportedAt: req.Timestamp,
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Store location"})
+		return
+	}
+
+	res := convertToLocationResponse(entry)
+	for _, detonation := range config.Detonator.TripMines(game, user, entry) {
+		if detonation.TrippedBy != nil {
+			res.TrippedMines = append(res.TrippedMines, detonation.IDBomb)
+		}
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, res)
+}
+
+// GetLastLocationHandler godoc
+// @Summary      Get the last location of a player
+// @Description  Retrieves the last known position of a player of the game.
+// @Description  While the game goes on, players only see their own position and the ones of their teammates
+// @Tags         games
+// @Produce      json
+// @Param        id      path      string  true  "Game ID"
+// @Param        userId  path      string  true  "User ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.LocationResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Player is not a teammate of the caller"
+// @Failure      404  {object}  map[string]string  "Game not found or no known location"
+// @Router       /api/v1/games/{id}/players/{userId}/location [get]
+func (config *GameConfig) GetLastLocationHandler(w http.ResponseWriter, r *http.Request) {
+
+	id, idUser, ok := parseGameAndUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !config.checkTracked(w, r, id, idUser) {
+		return
+	}
+
+	entry, err := config.PositionRepository.FindLast(id, idUser)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "No known location for this player"})
+		return
+	}
+
+	render.JSON(w, r, convertToLocationResponse(entry))
+}
+
+// GetTrackHandler godoc
+// @Summary      Get the track of a player
+// @Description  Retrieves the positions reported by a player of the game, oldest first.
+// @Description  While the game goes on, players only see their own track and the ones of their teammates
+// @Tags         games
+// @Produce      json
+// @Param        id      path      string  true   "Game ID"
+// @Param        userId  path      string  true   "User ID"
+// @Param        from    query     string  false  "RFC 3339 timestamp, defaults to the first position"
+// @Param        to      query     string  false  "RFC 3339 timestamp, defaults to now"
+// @Security     BearerAuth
+// @Success      200  {array}   model.LocationResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id or timestamp"
+// @Failure      403  {object}  map[string]string  "Player is not a teammate of the caller"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to find track"
+// @Router       /api/v1/games/{id}/players/{userId}/track [get]
+func (config *GameConfig) GetTrackHandler(w http.ResponseWriter, r *http.Request) {
+
+	id, idUser, ok := parseGameAndUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !config.checkTracked(w, r, id, idUser) {
+		return
+	}
+
+	from, to, ok := parseTimeRange(w, r, time.Time{}, time.Now())
+	if !ok {
+		return
+	}
+
+	entries, err := config.PositionRepository.FindTrack(id, idUser, from, to)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find track"})
+		return
+	}
+
+	res := []*model.LocationResponse{}
+	for _, entry := range entries {
+		res = append(res, convertToLocationResponse(entry))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// Fetch the authenticated user and the game, checking the user plays in it
+func (config *GameConfig) findMemberGame(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*dbmodel.UserEntry, *dbmodel.GameEntry, bool) {
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return nil, nil, false
+	}
+
+	game, err := config.GameRepository.FindByUserId(user.IDUser)
+	if err != nil || game.IDGame != id {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"Error": "User is not part of this game"})
+		return nil, nil, false
+	}
+
+	return user, game, true
+}
+
+// Check the authenticated user may follow the player: only themselves and their teammates while the game
+// goes on, everyone once it is finished. Admins follow every player
+func (config *GameConfig) checkTracked(w http.ResponseWriter, r *http.Request, id uuid.UUID, idUser uuid.UUID) bool {
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return false
+	}
+
+	game, err := config.GameRepository.FindById(id)
+	if err != nil || !game.VisibleTo(user) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Game not found in the DB"})
+		return false
+	}
+	if game.IsFinished() || user.Role == dbmodel.UserRoleAdmin || user.IDUser == idUser {
+		return true
+	}
+
+	// Teams belong to a single game, sharing one means playing in this game together
+	player, err := config.UserRepository.FindOne("id_user", idUser.String())
+	if err != nil || user.IDTeam == nil || player.IDTeam == nil || *player.IDTeam != *user.IDTeam {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"Error": "Only the locations of teammates show until the game is finished"})
+		return false
+	}
+
+	return true
+}
+
+func parseGameAndUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	idUser, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid user Id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, idUser, true
+}
+
+// Read the from and to query parameters, falling back on the given defaults
+func parseTimeRange(w http.ResponseWriter, r *http.Request, from, to time.Time) (time.Time, time.Time, bool) {
+	var err error
+	if str := r.URL.Query().Get("from"); str != "" {
+		if from, err = time.Parse(time.RFC3339, str); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"Error": "Invalid from parameter, must be a RFC 3339 timestamp"})
+			return from, to, false
+		}
+	}
+	if str := r.URL.Query().Get("to"); str != "" {
+		if to, err = time.Parse(time.RFC3339, str); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"Error": "Invalid to parameter, must be a RFC 3339 timestamp"})
+			return from, to, false
+		}
+	}
+
+	return from, to, true
+}
+
+func convertToLocationResponse(entry *dbmodel.PositionEntry) *model.LocationResponse {
+	return &model.LocationResponse{
+		IDUser:     entry.IDUser,
+		IDGame:     entry.IDGame,
+		Lat:        entry.Lat,
+		Long:       entry.Long,
+		Accuracy:   entry.Accuracy,
+		RecordedAt: entry.RecordedAt,
+		ReportedAt: entry.ReportedAt,
+	}
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+func TestReportLocationHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		status dbmodel.GameStatus
+		user   string
+		offset *time.Duration // Of the timestamp sent by the device from now, nil to send none
+		want   int
+	}{
+		{"without timestamp", dbmodel.GameStatusRunning, "bob", nil, http.StatusCreated},
+		{"recent timestamp", dbmodel.GameStatusRunning, "bob", durationPtr(-20 * time.Second), http.StatusCreated},
+		{"timestamp too old", dbmodel.GameStatusRunning, "bob", durationPtr(-10 * time.Minute), http.StatusBadRequest},
+		{"timestamp in the future", dbmodel.GameStatusRunning, "bob", durationPtr(10 * time.Minute), http.StatusBadRequest},
+		{"player of another game", dbmodel.GameStatusRunning, "eve", nil, http.StatusForbidden},
+		{"game not started", dbmodel.GameStatusDraft, "bob", nil, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGameFixture(t, tt.status)
+			body := map[string]any{"lat": 48.85, "long": 2.35}
+			var sent time.Time
+			if tt.offset != nil {
+				sent = time.Now().Add(*tt.offset).Truncate(time.Second)
+				body["timestamp"] = sent
+			}
+
+			before := time.Now()
+			w := f.do(t, tt.user, http.MethodPost, "/"+f.game.IDGame.String()+"/location", body)
+			after := time.Now()
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			// The device can't date its locations, the server does
+			res := &model.LocationResponse{}
+			if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+				t.Fatal(err)
+			}
+			if res.RecordedAt.Before(before) || res.RecordedAt.After(after) {
+				t.Errorf("recorded at %s, want the reception between %s and %s", res.RecordedAt, before, after)
+			}
+			switch {
+			case tt.offset == nil && res.ReportedAt != nil:
+				t.Errorf("reported at %s, want nothing", res.ReportedAt)
+			case tt.offset != nil && (res.ReportedAt == nil || !res.ReportedAt.Equal(sent)):
+				t.Errorf("reported at %v, want %s", res.ReportedAt, sent)
+			}
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestGetLastLocationHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		status dbmodel.GameStatus
+		viewer string
+		want   int
+	}{
+		{"player themselves", dbmodel.GameStatusRunning, "bob", http.StatusOK},
+		{"teammate", dbmodel.GameStatusRunning, "dan", http.StatusOK},
+		{"opponent", dbmodel.GameStatusRunning, "alice", http.StatusForbidden},
+		{"admin", dbmodel.GameStatusRunning, "root", http.StatusOK},
+		{"outsider of the private game", dbmodel.GameStatusRunning, "eve", http.StatusNotFound},
+		{"opponent once the game is finished", dbmodel.GameStatusFinished, "alice", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGameFixture(t, tt.status)
+			f.addUser(t, "dan", uuid.New(), &f.game.Teams[1].IDTeam, dbmodel.UserRolePlayer)
+			bob := f.users["bob"].IDUser
+			f.create(t, &dbmodel.PositionEntry{IDGame: f.game.IDGame, IDUser: bob, Lat: 48.85, Long: 2.35, RecordedAt: time.Now()})
+
+			for _, path := range []string{"/location", "/track"} {
+				w := f.do(t, tt.viewer, http.MethodGet, "/"+f.game.IDGame.String()+"/players/"+bob.String()+path, nil)
+				if w.Code != tt.want {
+					t.Errorf("%s status = %d, want %d: %s", path, w.Code, tt.want, w.Body.String())
+				}
+			}
+		})
+	}
+}
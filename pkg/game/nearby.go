@@ -0,0 +1,74 @@
+package game
+
+import (
+	"net/http"
+	"strconv"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/render"
+)
+
+const (
+	defaultNearbyRadius = 5000  // Meters
+	maxNearbyRadius     = 50000 // Meters
+)
+
+// GetNearbyHandler godoc
+// @Summary      Find the public games near a point
+// @Description  Lists the upcoming and running public games whose play area is within the radius of the point, closest first then soonest
+// @Tags         games
+// @Produce      json
+// @Param        lat     query     number  true   "Latitude"
+// @Param        long    query     number  true   "Longitude"
+// @Param        radius  query     number  false  "Radius in meters, 5000 by default, at most 50000"
+// @Security     BearerAuth
+// @Success      200  {array}   model.NearbyGameResponse
+// @Failure      400  {object}  map[string]string  "Invalid lat, long or radius"
+// @Failure      500  {object}  map[string]string  "Failed to find games"
+// @Router       /api/v1/games/nearby [get]
+func (config *GameConfig) GetNearbyHandler(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid lat parameter, must be between -90 / 90"})
+		return
+	}
+	long, err := strconv.ParseFloat(query.Get("long"), 64)
+	if err != nil || long < -180 || long > 180 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid long parameter, must be between -180 / 180"})
+		return
+	}
+
+	radius := float64(defaultNearbyRadius)
+	if radiusStr := query.Get("radius"); radiusStr != "" {
+		radius, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius < 0 || radius > maxNearbyRadius {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"Error": "Invalid radius parameter, must be between 0 and 50000 meters"})
+			return
+		}
+	}
+
+	center := geo.Point{Lat: lat, Long: long}
+	entries, err := config.GameRepository.FindNearby(geo.Circle{Center: center, Radius: radius})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find Games"})
+		return
+	}
+
+	res := []model.NearbyGameResponse{}
+	for _, game := range entries {
+		res = append(res, model.NearbyGameResponse{
+			GameResponse: *convertToResponse(game),
+			Distance:     game.DistanceTo(center),
+		})
+	}
+
+	render.JSON(w, r, res)
+}
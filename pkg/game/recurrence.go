@@ -0,0 +1,373 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+// PostRecurrenceHandler godoc
+// @Summary      Create a recurrence
+// @Description  Creates games from a template every day or on some weekdays, ahead of their start
+// @Tags         recurrences
+// @Accept       json
+// @Produce      json
+// @Param        recurrence  body      model.RecurrenceRequest  true  "Recurrence payload"
+// @Security     BearerAuth
+// @Success      201  {object}  model.RecurrenceResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload"
+// @Failure      404  {object}  map[string]string  "Template not found"
+// @Failure      500  {object}  map[string]string  "Failed to create recurrence"
+// @Router       /api/v1/recurrences [post]
+func (config *GameConfig) PostRecurrenceHandler(w http.ResponseWriter, r *http.Request) {
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	req := &model.RecurrenceRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Recurrence request payload. " + err.Error()})
+		return
+	}
+
+	if _, err := config.TemplateRepository.FindById(req.IDTemplate); err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Template not found in the DB"})
+		return
+	}
+
+	entry := &dbmodel.RecurrenceEntry{
+		IDOwner:    user.IDUser,
+		IDTemplate: req.IDTemplate,
+		Frequency:  dbmodel.RecurrenceFrequency(req.Frequency),
+		StartTime:  req.StartTime,
+		Location:   req.Timezone,
+		StartsOn:   time.Now(),
+		EndsOn:     req.EndsOn,
+	}
+	if req.StartsOn != nil {
+		entry.StartsOn = *req.StartsOn
+	}
+	if entry.Frequency == dbmodel.RecurrenceWeekly {
+		for _, day := range req.Weekdays {
+			weekday, _ := model.ParseWeekday(day)
+			entry.Weekdays = append(entry.Weekdays, weekday)
+		}
+	}
+
+	entry, err = config.RecurrenceRepository.Create(entry)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Create Recurrence"})
+		return
+	}
+
+	// Create the first games right away instead of waiting for the next round,
+	// a recurrence without them is dropped along with the games already created
+	if _, err := config.Materialiser.Materialise(entry, time.Now()); err != nil {
+		if err := config.RecurrenceRepository.Delete(entry.IDRecurrence); err != nil {
+			log.Printf("Failed to delete recurrence %s: %s\n", entry.IDRecurrence, err.Error())
+		}
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to create the games of the Recurrence"})
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	config.renderRecurrence(w, r, entry)
+}
+
+// GetRecurrencesHandler godoc
+// @Summary      Get my recurrences
+// @Description  Retrieves the recurrences created by the authenticated user
+// @Tags         recurrences
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   model.RecurrenceResponse
+// @Failure      500  {object}  map[string]string  "Failed to retrieve recurrences"
+// @Router       /api/v1/recurrences [get]
+func (config *GameConfig) GetRecurrencesHandler(w http.ResponseWriter, r *http.Request) {
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	entries, err := config.RecurrenceRepository.FindByOwner(user.IDUser)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find Recurrences"})
+		return
+	}
+
+	res := []*model.RecurrenceResponse{}
+	for _, entry := range entries {
+		res = append(res, convertToRecurrenceResponse(entry))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// GetRecurrenceHandler godoc
+// @Summary      Get a recurrence
+// @Description  Retrieves a recurrence and its upcoming occurrences, cancelled ones included
+// @Tags         recurrences
+// @Produce      json
+// @Param        id   path      string  true  "Recurrence ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.RecurrenceResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the owner of the recurrence"
+// @Failure      404  {object}  map[string]string  "Recurrence not found"
+// @Router       /api/v1/recurrences/{id} [get]
+func (config *GameConfig) GetRecurrenceHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findOwnedRecurrence(w, r)
+	if !ok {
+		return
+	}
+
+	config.renderRecurrence(w, r, entry)
+}
+
+// PauseRecurrenceHandler godoc
+// @Summary      Pause a recurrence
+// @Description  Stops creating new games, the games already created are kept
+// @Tags         recurrences
+// @Produce      json
+// @Param        id   path      string  true  "Recurrence ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.RecurrenceResponse
+// @Failure      403  {object}  map[string]string  "Not the owner of the recurrence"
+// @Failure      404  {object}  map[string]string  "Recurrence not found"
+// @Failure      409  {object}  map[string]string  "Recurrence is not active"
+// @Router       /api/v1/recurrences/{id}/pause [post]
+func (config *GameConfig) PauseRecurrenceHandler(w http.ResponseWriter, r *http.Request) {
+	config.changeRecurrenceStatus(w, r, dbmodel.RecurrenceStatusActive, dbmodel.RecurrenceStatusPaused)
+}
+
+// ResumeRecurrenceHandler godoc
+// @Summary      Resume a recurrence
+// @Description  Creates the games of a paused recurrence again, occurrences missed while paused are skipped
+// @Tags         recurrences
+// @Produce      json
+// @Param        id   path      string  true  "Recurrence ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.RecurrenceResponse
+// @Failure      403  {object}  map[string]string  "Not the owner of the recurrence"
+// @Failure      404  {object}  map[string]string  "Recurrence not found"
+// @Failure      409  {object}  map[string]string  "Recurrence is not paused"
+// @Router       /api/v1/recurrences/{id}/resume [post]
+func (config *GameConfig) ResumeRecurrenceHandler(w http.ResponseWriter, r *http.Request) {
+	config.changeRecurrenceStatus(w, r, dbmodel.RecurrenceStatusPaused, dbmodel.RecurrenceStatusActive)
+}
+
+// CancelRecurrenceHandler godoc
+// @Summary      Cancel a recurrence
+// @Description  Stops the recurrence for good and removes the games of its upcoming occurrences that have not started
+// @Tags         recurrences
+// @Produce      json
+// @Param        id   path      string  true  "Recurrence ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.RecurrenceResponse
+// @Failure      403  {object}  map[string]string  "Not the owner of the recurrence"
+// @Failure      404  {object}  map[string]string  "Recurrence not found"
+// @Failure      409  {object}  map[string]string  "Recurrence already cancelled"
+// @Failure      500  {object}  map[string]string  "Failed to cancel recurrence"
+// @Router       /api/v1/recurrences/{id}/cancel [post]
+func (config *GameConfig) CancelRecurrenceHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findOwnedRecurrence(w, r)
+	if !ok {
+		return
+	}
+
+	if entry.Status == dbmodel.RecurrenceStatusCancelled {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Recurrence is already cancelled"})
+		return
+	}
+
+	if err := config.RecurrenceRepository.Cancel(entry.IDRecurrence, time.Now()); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Cancel Recurrence"})
+		return
+	}
+
+	entry.Status = dbmodel.RecurrenceStatusCancelled
+	config.renderRecurrence(w, r, entry)
+}
+
+// CancelOccurrenceHandler godoc
+// @Summary      Cancel an occurrence
+// @Description  Removes the game of an upcoming occurrence, the occurrence is kept as cancelled so it is not created again
+// @Tags         recurrences
+// @Produce      json
+// @Param        id            path      string  true  "Recurrence ID"
+// @Param        occurrenceId  path      string  true  "Occurrence ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.OccurrenceResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Not the owner of the recurrence"
+// @Failure      404  {object}  map[string]string  "Occurrence not found"
+// @Failure      409  {object}  map[string]string  "Occurrence already cancelled or started"
+// @Failure      500  {object}  map[string]string  "Failed to cancel occurrence"
+// @Router       /api/v1/recurrences/{id}/occurrences/{occurrenceId}/cancel [post]
+func (config *GameConfig) CancelOccurrenceHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findOwnedRecurrence(w, r)
+	if !ok {
+		return
+	}
+
+	idOccurrence, err := uuid.Parse(chi.URLParam(r, "occurrenceId"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid occurrence Id"})
+		return
+	}
+
+	occurrence, err := config.RecurrenceRepository.FindOccurrence(entry.IDRecurrence, idOccurrence)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Occurrence not found in the DB"})
+		return
+	}
+
+	if occurrence.Status == dbmodel.OccurrenceStatusCancelled {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Occurrence is already cancelled"})
+		return
+	}
+
+	err = config.RecurrenceRepository.CancelOccurrence(occurrence)
+	if errors.Is(err, dbmodel.ErrOccurrenceStarted) {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "The game of the occurrence has already started"})
+		return
+	}
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Cancel Occurrence"})
+		return
+	}
+
+	render.JSON(w, r, convertToOccurrenceResponse(occurrence))
+}
+
+func (config *GameConfig) changeRecurrenceStatus(w http.ResponseWriter, r *http.Request, from, to dbmodel.RecurrenceStatus) {
+
+	entry, ok := config.findOwnedRecurrence(w, r)
+	if !ok {
+		return
+	}
+
+	if err := config.RecurrenceRepository.UpdateStatus(entry.IDRecurrence, from, to); err != nil {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Recurrence is " + string(entry.Status) + ", it cannot become " + string(to)})
+		return
+	}
+	entry.Status = to
+
+	// Fill the look ahead window again once resumed
+	if to == dbmodel.RecurrenceStatusActive {
+		if _, err := config.Materialiser.Materialise(entry, time.Now()); err != nil {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"Error": "Failed to create the games of the Recurrence"})
+			return
+		}
+	}
+
+	config.renderRecurrence(w, r, entry)
+}
+
+// Fetch the recurrence of the URL, only if the authenticated user owns it
+func (config *GameConfig) findOwnedRecurrence(w http.ResponseWriter, r *http.Request) (*dbmodel.RecurrenceEntry, bool) {
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+		return nil, false
+	}
+
+	entry, err := config.RecurrenceRepository.FindById(id)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Recurrence not found in the DB"})
+		return nil, false
+	}
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil || user.IDUser != entry.IDOwner {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"Error": "Only the owner of the recurrence can manage it"})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Render the recurrence along with its upcoming occurrences
+func (config *GameConfig) renderRecurrence(w http.ResponseWriter, r *http.Request, entry *dbmodel.RecurrenceEntry) {
+
+	upcoming, err := config.RecurrenceRepository.FindUpcoming(entry.IDRecurrence, time.Now())
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find Occurrences"})
+		return
+	}
+
+	res := convertToRecurrenceResponse(entry)
+	res.Upcoming = []model.OccurrenceResponse{}
+	for _, occurrence := range upcoming {
+		res.Upcoming = append(res.Upcoming, *convertToOccurrenceResponse(occurrence))
+	}
+
+	render.JSON(w, r, res)
+}
+
+func convertToRecurrenceResponse(entry *dbmodel.RecurrenceEntry) *model.RecurrenceResponse {
+
+	res := &model.RecurrenceResponse{
+		IDRecurrence: entry.IDRecurrence,
+		IDTemplate:   entry.IDTemplate,
+		Frequency:    string(entry.Frequency),
+		Weekdays:     []string{},
+		StartTime:    entry.StartTime,
+		Timezone:     entry.Location,
+		StartsOn:     entry.StartsOn,
+		EndsOn:       entry.EndsOn,
+		Status:       string(entry.Status),
+	}
+	for _, weekday := range entry.Weekdays {
+		res.Weekdays = append(res.Weekdays, weekday.String())
+	}
+
+	return res
+}
+
+func convertToOccurrenceResponse(entry *dbmodel.OccurrenceEntry) *model.OccurrenceResponse {
+	return &model.OccurrenceResponse{
+		IDOccurrence: entry.IDOccurrence,
+		OccurrenceAt: entry.OccurrenceAt,
+		IDGame:       entry.IDGame,
+		Status:       string(entry.Status),
+	}
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"net/http"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/render"
+)
+
+// GetResultsHandler godoc
+// @Summary      Get the results of a game
+// @Description  Retrieves the standings frozen when the game finished: team ranking, player contributions, bombs used per type and duration
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameResultResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      404  {object}  map[string]string  "Game or results not found"
+// @Failure      409  {object}  map[string]string  "Game is not finished"
+// @Router       /api/v1/games/{id}/results [get]
+func (config *GameConfig) GetResultsHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	if !game.IsFinished() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Game is not finished yet"})
+		return
+	}
+
+	entry, err := config.ResultRepository.FindByGame(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Results not found in the DB"})
+		return
+	}
+
+	render.JSON(w, r, convertToResultResponse(entry))
+}
+
+func convertToResultResponse(entry *dbmodel.GameResultEntry) *model.GameResultResponse {
+	res := &model.GameResultResponse{
+		IDGame:      entry.IDGame,
+		FinishedAt:  entry.FinishedAt,
+		Duration:    entry.Duration,
+		Teams:       []model.ResultTeamResponse{},
+		Players:     []model.ResultPlayerResponse{},
+		BombsByType: entry.BombsByType,
+	}
+	for _, team := range entry.Teams {
+		res.Teams = append(res.Teams, model.ResultTeamResponse{
+			Rank:   team.Rank,
+			IDTeam: team.IDTeam,
+			Name:   team.Name,
+			Color:  team.Color,
+			Score:  team.Score,
+		})
+	}
+	for _, player := range entry.Players {
+		res.Players = append(res.Players, model.ResultPlayerResponse{
+			IDUser:   player.IDUser,
+			UserName: player.UserName,
+			IDTeam:   player.IDTeam,
+			Points:   player.Points,
+			Bombs:    player.Bombs,
+		})
+	}
+	return res
+}
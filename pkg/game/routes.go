@@ -3,6 +3,7 @@ package game
 import (
 	"bombparty.com/bombparty-api/config"
 	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/bomb"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -11,6 +12,7 @@ func Routes(configuration *config.Config) chi.Router {
 
 	// Init Router
 	gameConfig := New(configuration)
+	bombConfig := bomb.New(configuration)
 	router := chi.NewRouter()
 
 	// Routes protected by authentication
@@ -18,10 +20,66 @@ func Routes(configuration *config.Config) chi.Router {
 		router.Use(authentication.AuthMiddleware(configuration.JwtKey))
 
 		router.Get("/", gameConfig.GetAlldHandler)
+		router.Get("/nearby", gameConfig.GetNearbyHandler)
 		router.Get("/{id}", gameConfig.GetByIdHandler)
 		router.Post("/", gameConfig.PostHandler)
+		router.Post("/from-template/{templateId}", gameConfig.PostFromTemplateHandler)
+		router.Post("/join", gameConfig.JoinHandler)
 		router.Patch("/{id}", gameConfig.UpdateHandler)
 		router.Delete("/{id}", gameConfig.DeleteHandler)
+		router.Get("/{id}/zone", gameConfig.GetZoneHandler)
+		router.Get("/{id}/territory", gameConfig.GetTerritoryHandler)
+		router.Get("/{id}/bombs", bombConfig.GetGameBombs)
+		router.Get("/{id}/events", gameConfig.GetEventsHandler)
+		router.Get("/{id}/results", gameConfig.GetResultsHandler)
+
+		// Scores
+		router.Get("/{id}/scores", gameConfig.GetScoresHandler)
+		router.Group(func(router chi.Router) {
+			router.Use(authentication.AdminMiddleware(configuration.UserRepository))
+
+			router.Post("/{id}/scores", gameConfig.PostScoreHandler)
+			router.Post("/{id}/scores/recompute", gameConfig.RecomputeScoresHandler)
+		})
+
+		// Lobby
+		router.Get("/{id}/lobby", gameConfig.GetLobbyHandler)
+		router.Post("/{id}/join-code", gameConfig.RegenerateJoinCodeHandler)
+		router.Post("/{id}/rebalance", gameConfig.RebalanceHandler)
+
+		// Player locations
+		router.Post("/{id}/location", gameConfig.ReportLocationHandler)
+		router.Get("/{id}/players/{userId}/location", gameConfig.GetLastLocationHandler)
+		router.Get("/{id}/players/{userId}/track", gameConfig.GetTrackHandler)
+
+		// Lifecycle
+		router.Post("/{id}/schedule", gameConfig.ScheduleHandler)
+		router.Post("/{id}/start", gameConfig.StartHandler)
+		router.Post("/{id}/pause", gameConfig.PauseHandler)
+		router.Post("/{id}/resume", gameConfig.ResumeHandler)
+		router.Post("/{id}/finish", gameConfig.FinishHandler)
+	})
+
+	return router
+}
+
+func RecurrenceRoutes(configuration *config.Config) chi.Router {
+
+	// Init Router
+	gameConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(configuration.JwtKey))
+
+		router.Get("/", gameConfig.GetRecurrencesHandler)
+		router.Post("/", gameConfig.PostRecurrenceHandler)
+		router.Get("/{id}", gameConfig.GetRecurrenceHandler)
+		router.Post("/{id}/pause", gameConfig.PauseRecurrenceHandler)
+		router.Post("/{id}/resume", gameConfig.ResumeRecurrenceHandler)
+		router.Post("/{id}/cancel", gameConfig.CancelRecurrenceHandler)
+		router.Post("/{id}/occurrences/{occurrenceId}/cancel", gameConfig.CancelOccurrenceHandler)
 	})
 
 	return router
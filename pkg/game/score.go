@@ -0,0 +1,158 @@
+package game
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/render"
+)
+
+// GetScoresHandler godoc
+// @Summary      Get the score events of a game
+// @Description  Lists every change of the team scores of the game, oldest first, with who scored and why
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {array}   model.ScoreEventResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to find score events"
+// @Router       /api/v1/games/{id}/scores [get]
+func (config *GameConfig) GetScoresHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := config.ScoreRepository.FindByGame(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find score events"})
+		return
+	}
+
+	res := []*model.ScoreEventResponse{}
+	for _, entry := range entries {
+		res = append(res, convertToScoreEventResponse(entry))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// PostScoreHandler godoc
+// @Summary      Adjust the score of a team
+// @Description  Records a manual score event for a team of the game, admins only
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                        true  "Game ID"
+// @Param        score  body      model.ScoreAdjustmentRequest  true  "Points and reason of the adjustment"
+// @Security     BearerAuth
+// @Success      201  {object}  model.ScoreEventResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id, payload or team"
+// @Failure      403  {object}  map[string]string  "Admin role required"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      409  {object}  map[string]string  "Game not running nor paused"
+// @Failure      500  {object}  map[string]string  "Failed to record the score event"
+// @Router       /api/v1/games/{id}/scores [post]
+func (config *GameConfig) PostScoreHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	req := &model.ScoreAdjustmentRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+
+	// Results are frozen once the game is finished
+	if !game.AcceptsScoreChanges() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"Error": "Scores cannot be adjusted while the game is " + string(game.Status)})
+		return
+	}
+
+	if !slices.Contains(teamIds(game), req.IDTeam) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Team is not part of the game"})
+		return
+	}
+
+	entry, err := config.ScoreRepository.Record(&dbmodel.ScoreEventEntry{
+		IDGame:    game.IDGame,
+		IDTeam:    req.IDTeam,
+		IDUser:    authentication.CurrentUserId(r, config.UserRepository),
+		Reason:    dbmodel.ScoreReasonManual,
+		Points:    req.Points,
+		Objective: req.Objective,
+		At:        time.Now(),
+	})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Record the score event"})
+		return
+	}
+	config.Events.ScoreChanged(entry)
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, convertToScoreEventResponse(entry))
+}
+
+// RecomputeScoresHandler godoc
+// @Summary      Recompute the scores of a game
+// @Description  Rebuilds the score of every team of the game from its score events, admins only
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true  "Game ID"
+// @Security     BearerAuth
+// @Success      200  {array}   model.TeamScoreResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      403  {object}  map[string]string  "Admin role required"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to recompute the scores"
+// @Router       /api/v1/games/{id}/scores/recompute [post]
+func (config *GameConfig) RecomputeScoresHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	scores, err := config.ScoreRepository.Recompute(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Recompute the scores"})
+		return
+	}
+
+	res := []model.TeamScoreResponse{}
+	for _, team := range game.Teams {
+		res = append(res, model.TeamScoreResponse{IDTeam: team.IDTeam, Name: team.Name, Score: scores[team.IDTeam]})
+	}
+
+	render.JSON(w, r, res)
+}
+
+func convertToScoreEventResponse(entry *dbmodel.ScoreEventEntry) *model.ScoreEventResponse {
+	return &model.ScoreEventResponse{
+		ID:        entry.IDScoreEvent,
+		IDGame:    entry.IDGame,
+		IDTeam:    entry.IDTeam,
+		IDUser:    entry.IDUser,
+		Reason:    string(entry.Reason),
+		Points:    entry.Points,
+		IDBomb:    entry.IDBomb,
+		Objective: entry.Objective,
+		At:        entry.At,
+	}
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/pkg/authentication"
+)
+
+// PostFromTemplateHandler godoc
+// @Summary      Create a game from a template
+// @Description  Creates a game and its teams from a template, the dates are resolved from now
+// @Tags         games
+// @Produce      json
+// @Param        templateId  path      string  true  "Template ID"
+// @Security     BearerAuth
+// @Success      201  {object}  model.GameResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id or zone schedule"
+// @Failure      404  {object}  map[string]string  "Template not found"
+// @Failure      500  {object}  map[string]string  "Failed to create Game"
+// @Router       /api/v1/games/from-template/{templateId} [post]
+func (config *GameConfig) PostFromTemplateHandler(w http.ResponseWriter, r *http.Request) {
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	// Get the id in the URL
+	id, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+		return
+	}
+
+	template, err := config.TemplateRepository.FindById(id)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Template not found in the DB"})
+		return
+	}
+
+	gameEntry := template.Instantiate(time.Now())
+	gameEntry.IDHost = user.IDUser
+	if err := prepareZoneSchedule(gameEntry); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+
+	// The game and its teams are created together
+	entries, err := config.GameRepository.Create(gameEntry)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Create Game"})
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, convertToResponse(entries))
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/hexgrid"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// GetTerritoryHandler godoc
+// @Summary      Get the territory of a game
+// @Description  Retrieves the hex cells of a territory game and their owners as a GeoJSON FeatureCollection
+// @Tags         games
+// @Produce      json
+// @Param        id     path      string  true   "Game ID"
+// @Param        owned  query     bool    false  "Only return the captured cells"
+// @Security     BearerAuth
+// @Success      200  {object}  model.TerritoryResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id or not a territory game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Failure      500  {object}  map[string]string  "Failed to find territory"
+// @Router       /api/v1/games/{id}/territory [get]
+func (config *GameConfig) GetTerritoryHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	if game.Mode != dbmodel.GameModeTerritory {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Game is not a territory game"})
+		return
+	}
+
+	captured, err := config.TerritoryRepository.FindByGame(game.IDGame)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find territory"})
+		return
+	}
+
+	owners := map[hexgrid.Cell]*dbmodel.TerritoryCellEntry{}
+	for _, cell := range captured {
+		owners[hexgrid.Cell{Q: cell.Q, R: cell.R}] = cell
+	}
+
+	colors := map[uuid.UUID]string{}
+	for _, team := range game.Teams {
+		colors[team.IDTeam] = team.Color
+	}
+
+	grid := game.Grid()
+	cells := grid.Cover(game.Circle())
+	if r.URL.Query().Get("owned") == "true" {
+		cells = cells[:0]
+		for cell := range owners {
+			cells = append(cells, cell)
+		}
+		slices.SortFunc(cells, func(a, b hexgrid.Cell) int {
+			return cmp.Or(cmp.Compare(a.Q, b.Q), cmp.Compare(a.R, b.R))
+		})
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.TerritoryResponse{Type: "FeatureCollection", Features: []model.TerritoryFeature{}}
+	for _, cell := range cells {
+		properties := model.TerritoryCellProperties{Q: cell.Q, R: cell.R}
+		if owner, ok := owners[cell]; ok {
+			properties.IDTeam = &owner.IDTeam
+			properties.Color = colors[owner.IDTeam]
+			properties.CapturedAt = &owner.CapturedAt
+		}
+
+		res.Features = append(res.Features, model.TerritoryFeature{
+			Type:       "Feature",
+			Geometry:   geo.MultiPolygon{geo.Polygon{grid.Boundary(cell)}},
+			Properties: properties,
+		})
+	}
+
+	render.JSON(w, r, res)
+}
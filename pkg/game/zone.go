@@ -0,0 +1,77 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/render"
+)
+
+// GetZoneHandler godoc
+// @Summary      Get the zone of a game
+// @Description  Retrieves the zone in force at the given moment and the next announced zone.
+// @Description  Only the host and the admins can look ahead of now, players only learn of a zone once announced
+// @Tags         games
+// @Produce      json
+// @Param        id   path      string  true   "Game ID"
+// @Param        at   query     string  false  "RFC 3339 timestamp, defaults to now"
+// @Security     BearerAuth
+// @Success      200  {object}  model.ZoneResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id or timestamp"
+// @Failure      403  {object}  map[string]string  "Moment ahead of now and not the host of the game"
+// @Failure      404  {object}  map[string]string  "Game not found"
+// @Router       /api/v1/games/{id}/zone [get]
+func (config *GameConfig) GetZoneHandler(w http.ResponseWriter, r *http.Request) {
+
+	game, ok := config.findGame(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	at := time.Now()
+	if atStr := r.URL.Query().Get("at"); atStr != "" {
+		at, err = time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"Error": "Invalid at parameter, must be a RFC 3339 timestamp"})
+			return
+		}
+	}
+
+	// Zones ahead would give away where the zone ends before it is announced
+	if at.After(time.Now()) {
+		user, err := authentication.CurrentUser(r, config.UserRepository)
+		if err != nil || !game.ManagedBy(user) {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, map[string]string{"Error": "Only the host of the game can look at the zone ahead"})
+			return
+		}
+	}
+
+	state := game.ZoneAt(at)
+	res := &model.ZoneResponse{
+		At:      at,
+		Current: convertToZoneCircle(state.Current),
+	}
+	if state.Next != nil {
+		res.Next = &model.NextZone{
+			ZoneCircle:     convertToZoneCircle(*state.Next),
+			ShrinkStartsAt: state.ShrinkStartsAt,
+			ShrinkEndsAt:   state.ShrinkEndsAt,
+		}
+	}
+
+	render.JSON(w, r, res)
+}
+
+func convertToZoneCircle(circle geo.Circle) model.ZoneCircle {
+	return model.ZoneCircle{
+		CenterLatitude:  circle.Center.Lat,
+		CenterLongitude: circle.Center.Long,
+		Radius:          circle.Radius,
+	}
+}
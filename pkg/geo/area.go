@@ -0,0 +1,41 @@
+package geo
+
+import "errors"
+
+var (
+	ErrOutsideArea   = errors.New("outside of the play area")
+	ErrOutsideZone   = errors.New("outside of the current zone")
+	ErrExclusionZone = errors.New("inside an exclusion zone")
+)
+
+type Shape interface {
+	Contains(p Point) bool
+}
+
+// Play area made of a boundary, restricted to the current zone if any,
+// where the exclusion zones are cut out
+type PlayArea struct {
+	Boundary   Shape
+	Zone       Shape
+	Exclusions []Shape
+}
+
+// Return nil if the point can be played, the reason why it cannot otherwise
+func (a PlayArea) Check(p Point) error {
+	if !a.Boundary.Contains(p) {
+		return ErrOutsideArea
+	}
+	if a.Zone != nil && !a.Zone.Contains(p) {
+		return ErrOutsideZone
+	}
+	for _, exclusion := range a.Exclusions {
+		if exclusion.Contains(p) {
+			return ErrExclusionZone
+		}
+	}
+	return nil
+}
+
+func (a PlayArea) Contains(p Point) bool {
+	return a.Check(p) == nil
+}
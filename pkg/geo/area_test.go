@@ -0,0 +1,29 @@
+package geo
+
+import "testing"
+
+func TestPlayAreaCheck(t *testing.T) {
+	center := Point{Lat: 48.85, Long: 2.35}
+	area := PlayArea{
+		Boundary:   Circle{Center: center, Radius: 500},
+		Zone:       Circle{Center: center, Radius: 200},
+		Exclusions: []Shape{Circle{Center: north(center, 100), Radius: 20}},
+	}
+	tests := []struct {
+		name string
+		p    Point
+		want error
+	}{
+		{"playable", center, nil},
+		{"outside the area", north(center, 600), ErrOutsideArea},
+		{"outside the zone", north(center, 300), ErrOutsideZone},
+		{"in an exclusion zone", north(center, 105), ErrExclusionZone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := area.Check(tt.p); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
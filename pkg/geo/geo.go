@@ -0,0 +1,60 @@
+// Package geo holds the geodesic helpers shared by the handlers.
+package geo
+
+import "math"
+
+// Mean earth radius in meters
+const EarthRadius = 6371008.8
+
+type Point struct {
+	Lat  float64 `json:"lat"`
+	Long float64 `json:"long"`
+}
+
+func NewPoint(lat, long float32) Point {
+	return Point{Lat: float64(lat), Long: float64(long)}
+}
+
+// Great-circle distance in meters between two points, using the haversine formula
+func Distance(a, b Point) float64 {
+	lat1 := toRadians(a.Lat)
+	lat2 := toRadians(b.Lat)
+	dLat := lat2 - lat1
+	dLong := toRadians(b.Long - a.Long)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLong/2)*math.Sin(dLong/2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Point reached from p after moving distance meters toward the bearing, in degrees from north
+func Destination(p Point, bearing, distance float64) Point {
+	lat := toRadians(p.Lat)
+	long := toRadians(p.Long)
+	theta := toRadians(bearing)
+	delta := distance / EarthRadius
+
+	lat2 := math.Asin(math.Sin(lat)*math.Cos(delta) + math.Cos(lat)*math.Sin(delta)*math.Cos(theta))
+	long2 := long + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat), math.Cos(delta)-math.Sin(lat)*math.Sin(lat2))
+
+	return Point{Lat: toDegrees(lat2), Long: toDegrees(long2)}
+}
+
+// Circle on the earth surface, radius in meters
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+func (c Circle) Contains(p Point) bool {
+	return Distance(c.Center, p) <= c.Radius
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
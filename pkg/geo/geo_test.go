@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64
+	}{
+		{"same point", Point{Lat: 48.85, Long: 2.35}, Point{Lat: 48.85, Long: 2.35}, 0},
+		{"one degree of latitude", Point{Lat: 0, Long: 0}, Point{Lat: 1, Long: 0}, 111195},
+		{"one degree of longitude on the equator", Point{Lat: 0, Long: 0}, Point{Lat: 0, Long: 1}, 111195},
+		{"paris to london", Point{Lat: 48.8566, Long: 2.3522}, Point{Lat: 51.5074, Long: -0.1278}, 343556},
+		{"antipodes", Point{Lat: 0, Long: 0}, Point{Lat: 0, Long: 180}, math.Pi * EarthRadius},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.a, tt.b); math.Abs(got-tt.want) > 1 {
+				t.Errorf("Distance() = %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestination(t *testing.T) {
+	start := Point{Lat: 48.85, Long: 2.35}
+	tests := []struct {
+		name     string
+		bearing  float64
+		distance float64
+	}{
+		{"north", 0, 1000},
+		{"east", 90, 250},
+		{"south west", 225, 5000},
+		{"nowhere", 42, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := Destination(start, tt.bearing, tt.distance)
+			if got := Distance(start, end); math.Abs(got-tt.distance) > 0.01 {
+				t.Errorf("Distance() to the destination = %.3f, want %.3f", got, tt.distance)
+			}
+		})
+	}
+}
+
+func TestCircleContains(t *testing.T) {
+	circle := Circle{Center: Point{Lat: 48.85, Long: 2.35}, Radius: 100}
+	tests := []struct {
+		name string
+		p    Point
+		want bool
+	}{
+		{"center", circle.Center, true},
+		{"inside", north(circle.Center, 99), true},
+		{"outside", north(circle.Center, 101), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := circle.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Point the distance north of p, a degree of latitude spans about 111195 meters
+func north(p Point, meters float64) Point {
+	return Point{Lat: p.Lat + meters/111195, Long: p.Long}
+}
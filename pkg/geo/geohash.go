@@ -0,0 +1,112 @@
+package geo
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Precision of the geohashes stored alongside the points, cells of about 5 x 5 meters
+const GeohashPrecision = 9
+
+// Maximum number of cells used to cover a circle before falling back to a coarser precision
+const maxCoverCells = 16
+
+// Encode the point as a geohash of precision characters
+func Geohash(p Point, precision int) string {
+	latRange := [2]float64{-90, 90}
+	longRange := [2]float64{-180, 180}
+	lat := math.Max(-90, math.Min(90, p.Lat))
+	long := normalizeLong(p.Long)
+
+	var hash strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for hash.Len() < precision {
+		// Bits alternate between longitude and latitude, starting with longitude
+		value, bounds := lat, &latRange
+		if even {
+			value, bounds = long, &longRange
+		}
+
+		mid := (bounds[0] + bounds[1]) / 2
+		ch <<= 1
+		if value >= mid {
+			ch |= 1
+			bounds[0] = mid
+		} else {
+			bounds[1] = mid
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			hash.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return hash.String()
+}
+
+// Size in degrees of the latitude and longitude sides of a cell of precision characters
+func geohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	latBits := bits / 2
+	longBits := bits - latBits
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(longBits))
+}
+
+// Geohash prefixes of the cells covering the circle, using the finest precision
+// that keeps the number of cells small.
+// Every point of the circle has a geohash starting with one of the prefixes.
+func GeohashCover(circle Circle) []string {
+	north := Destination(circle.Center, 0, circle.Radius).Lat
+	south := Destination(circle.Center, 180, circle.Radius).Lat
+	dLong := 180.0
+	if north < 90 && south > -90 {
+		// Widest longitude span of the circle, reached at its center latitude
+		dLong = math.Abs(Destination(circle.Center, 90, circle.Radius).Long - circle.Center.Long)
+	}
+	west := circle.Center.Long - dLong
+	east := circle.Center.Long + dLong
+
+	for precision := GeohashPrecision; precision > 1; precision-- {
+		latSize, longSize := geohashCellSize(precision)
+		rows := int(math.Floor(north/latSize) - math.Floor(south/latSize) + 1)
+		cols := int(math.Floor(east/longSize) - math.Floor(west/longSize) + 1)
+		if rows*cols <= maxCoverCells {
+			return coverBox(south, north, west, east, precision)
+		}
+	}
+	return coverBox(south, north, west, east, 1)
+}
+
+// Geohashes of every cell intersecting the box, in ascending order
+func coverBox(south, north, west, east float64, precision int) []string {
+	latSize, longSize := geohashCellSize(precision)
+	seen := map[string]bool{}
+	var hashes []string
+
+	for lat := math.Floor(south/latSize) * latSize; lat <= north; lat += latSize {
+		for long := math.Floor(west/longSize) * longSize; long <= east; long += longSize {
+			// Sample the middle of the cell to stay clear of rounding at its edges
+			hash := Geohash(Point{Lat: lat + latSize/2, Long: long + longSize/2}, precision)
+			if !seen[hash] {
+				seen[hash] = true
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	slices.Sort(hashes)
+	return hashes
+}
+
+// Wrap a longitude into [-180, 180)
+func normalizeLong(long float64) float64 {
+	long = math.Mod(long+180, 360)
+	if long < 0 {
+		long += 360
+	}
+	return long - 180
+}
@@ -0,0 +1,57 @@
+package geo
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGeohash(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Point
+		precision int
+		want      string
+	}{
+		{"reference point", Point{Lat: 57.64911, Long: 10.40744}, 11, "u4pruydqqvj"},
+		{"origin", Point{Lat: 0, Long: 0}, 5, "s0000"},
+		{"south west corner", Point{Lat: -90, Long: -180}, 3, "000"},
+		{"wrapped longitude", Point{Lat: 57.64911, Long: 10.40744 + 360}, 11, "u4pruydqqvj"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Geohash(tt.p, tt.precision); got != tt.want {
+				t.Errorf("Geohash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeohashCover(t *testing.T) {
+	tests := []struct {
+		name   string
+		circle Circle
+	}{
+		{"small circle", Circle{Center: Point{Lat: 48.85, Long: 2.35}, Radius: 15}},
+		{"game sized circle", Circle{Center: Point{Lat: 48.85, Long: 2.35}, Radius: 2000}},
+		{"across the antimeridian", Circle{Center: Point{Lat: 0, Long: 179.9999}, Radius: 100}},
+		{"on a cell edge", Circle{Center: Point{Lat: 0, Long: 0}, Radius: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cover := GeohashCover(tt.circle)
+			if len(cover) == 0 || len(cover) > maxCoverCells*2 {
+				t.Fatalf("GeohashCover() returned %d cells", len(cover))
+			}
+			for bearing := 0.0; bearing < 360; bearing += 15 {
+				for _, ratio := range []float64{0, 0.5, 0.99} {
+					p := Destination(tt.circle.Center, bearing, tt.circle.Radius*ratio)
+					hash := Geohash(p, GeohashPrecision)
+					if !slices.ContainsFunc(cover, func(prefix string) bool { return strings.HasPrefix(hash, prefix) }) {
+						t.Errorf("point %v of geohash %s is not covered by %v", p, hash, cover)
+					}
+				}
+			}
+		})
+	}
+}
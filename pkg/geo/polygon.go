@@ -0,0 +1,148 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Closed ring of points, the last point repeats the first one
+type Ring []Point
+
+// Outer ring followed by its holes
+type Polygon []Ring
+
+type MultiPolygon []Polygon
+
+// GeoJSON geometry object, coordinates are [long, lat] positions
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Ray casting on the lat/long plane, precise enough at the scale of a game
+func (ring Ring) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Long < (b.Long-a.Long)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Long {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (polygon Polygon) Contains(p Point) bool {
+	if len(polygon) == 0 || !polygon[0].Contains(p) {
+		return false
+	}
+	for _, hole := range polygon[1:] {
+		if hole.Contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func (mp MultiPolygon) Contains(p Point) bool {
+	for _, polygon := range mp {
+		if polygon.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Accept a GeoJSON Polygon or MultiPolygon geometry
+func (mp *MultiPolygon) UnmarshalJSON(data []byte) error {
+	var g geometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+
+	var positions [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		positions = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &positions); err != nil {
+			return fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported geometry type %q, must be Polygon or MultiPolygon", g.Type)
+	}
+
+	if len(positions) == 0 {
+		return errors.New("geometry must contain at least one polygon")
+	}
+
+	result := make(MultiPolygon, 0, len(positions))
+	for _, rings := range positions {
+		if len(rings) == 0 {
+			return errors.New("polygon must contain at least one ring")
+		}
+		polygon := make(Polygon, 0, len(rings))
+		for _, coords := range rings {
+			ring, err := parseRing(coords)
+			if err != nil {
+				return err
+			}
+			polygon = append(polygon, ring)
+		}
+		result = append(result, polygon)
+	}
+
+	*mp = result
+	return nil
+}
+
+// Always written back as a GeoJSON MultiPolygon
+func (mp MultiPolygon) MarshalJSON() ([]byte, error) {
+	positions := make([][][][2]float64, 0, len(mp))
+	for _, polygon := range mp {
+		rings := make([][][2]float64, 0, len(polygon))
+		for _, ring := range polygon {
+			coords := make([][2]float64, 0, len(ring))
+			for _, p := range ring {
+				coords = append(coords, [2]float64{p.Long, p.Lat})
+			}
+			rings = append(rings, coords)
+		}
+		positions = append(positions, rings)
+	}
+
+	coordinates, err := json.Marshal(positions)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(geometry{Type: "MultiPolygon", Coordinates: coordinates})
+}
+
+func parseRing(coords [][]float64) (Ring, error) {
+	if len(coords) < 4 {
+		return nil, errors.New("ring must contain at least 4 positions")
+	}
+
+	ring := make(Ring, 0, len(coords))
+	for _, position := range coords {
+		if len(position) < 2 {
+			return nil, errors.New("position must contain a longitude and a latitude")
+		}
+		p := Point{Lat: position[1], Long: position[0]}
+		if p.Lat < -90 || p.Lat > 90 || p.Long < -180 || p.Long > 180 {
+			return nil, fmt.Errorf("position [%g, %g] is out of range", p.Long, p.Lat)
+		}
+		ring = append(ring, p)
+	}
+
+	if ring[0] != ring[len(ring)-1] {
+		return nil, errors.New("ring must be closed, the last position must repeat the first one")
+	}
+
+	return ring, nil
+}
@@ -0,0 +1,33 @@
+package geo
+
+import "testing"
+
+func TestPolygonContains(t *testing.T) {
+	square := func(min, max float64) Ring {
+		return Ring{{Lat: min, Long: min}, {Lat: min, Long: max}, {Lat: max, Long: max}, {Lat: max, Long: min}, {Lat: min, Long: min}}
+	}
+	withHole := Polygon{square(0, 10), square(4, 6)}
+	area := MultiPolygon{withHole, {square(20, 30)}}
+
+	tests := []struct {
+		name  string
+		shape Shape
+		p     Point
+		want  bool
+	}{
+		{"inside the ring", square(0, 10), Point{Lat: 5, Long: 5}, true},
+		{"outside the ring", square(0, 10), Point{Lat: 15, Long: 5}, false},
+		{"inside the hole", withHole, Point{Lat: 5, Long: 5}, false},
+		{"between the ring and the hole", withHole, Point{Lat: 2, Long: 2}, true},
+		{"empty polygon", Polygon{}, Point{Lat: 5, Long: 5}, false},
+		{"second polygon", area, Point{Lat: 25, Long: 25}, true},
+		{"between the polygons", area, Point{Lat: 15, Long: 15}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shape.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Once a phase is announced the zone holds for Warning seconds,
+// then shrinks linearly to Radius meters over Duration seconds
+type ZonePhase struct {
+	Radius   float64 `json:"radius"`
+	Duration int     `json:"duration"`
+	Warning  int     `json:"warning"`
+}
+
+// Phases of a battle-royale zone shrinking toward a final center.
+// Without a final center, it is picked at random when RandomCenter is set,
+// and is the center of the game otherwise
+type ZoneSchedule struct {
+	FinalCenter  *Point      `json:"final_center,omitempty"`
+	RandomCenter bool        `json:"random_center"`
+	Phases       []ZonePhase `json:"phases"`
+}
+
+// Zone in force at a given moment and the one announced next, if any
+type ZoneState struct {
+	Current        Circle
+	Next           *Circle
+	ShrinkStartsAt time.Time
+	ShrinkEndsAt   time.Time
+}
+
+// Check the phases against the initial circle of the game
+func (s *ZoneSchedule) Validate(initial Circle) error {
+	if len(s.Phases) == 0 {
+		return errors.New("zone schedule must contain at least one phase")
+	}
+
+	previous := initial.Radius
+	for i, phase := range s.Phases {
+		if phase.Radius <= 0 || phase.Radius >= previous {
+			return fmt.Errorf("zone phase %d radius must be positive and smaller than the previous one (%.0fm)", i+1, previous)
+		}
+		if phase.Duration < 0 || phase.Warning < 0 {
+			return fmt.Errorf("zone phase %d duration and warning must not be negative", i+1)
+		}
+		previous = phase.Radius
+	}
+
+	if s.FinalCenter != nil && Distance(initial.Center, *s.FinalCenter) > initial.Radius-s.finalRadius() {
+		return errors.New("zone final center must keep the final zone inside the game area")
+	}
+
+	return nil
+}
+
+// Fix the final center so every later computation of the zone gives the same result
+func (s *ZoneSchedule) ResolveCenter(initial Circle) {
+	if s.FinalCenter != nil {
+		return
+	}
+
+	center := initial.Center
+	if s.RandomCenter {
+		// Uniform pick in the disk keeping the final zone inside the game area
+		distance := math.Sqrt(rand.Float64()) * (initial.Radius - s.finalRadius())
+		center = Destination(initial.Center, rand.Float64()*360, distance)
+	}
+	s.FinalCenter = &center
+}
+
+// Zone in force at the given moment, the schedule starting with the game
+func (s *ZoneSchedule) At(start time.Time, initial Circle, at time.Time) ZoneState {
+	current := initial
+	phaseStart := start
+
+	for i, phase := range s.Phases {
+		target := Circle{Center: s.phaseCenter(initial, i), Radius: phase.Radius}
+		shrinkStart := phaseStart.Add(time.Duration(phase.Warning) * time.Second)
+		shrinkEnd := shrinkStart.Add(time.Duration(phase.Duration) * time.Second)
+
+		if at.Before(shrinkEnd) {
+			state := ZoneState{Current: current, Next: &target, ShrinkStartsAt: shrinkStart, ShrinkEndsAt: shrinkEnd}
+			if at.After(shrinkStart) {
+				progress := float64(at.Sub(shrinkStart)) / float64(shrinkEnd.Sub(shrinkStart))
+				state.Current = interpolate(current, target, progress)
+			}
+			return state
+		}
+
+		current = target
+		phaseStart = shrinkEnd
+	}
+
+	return ZoneState{Current: current}
+}
+
+// Moment the last phase is announced, revealing where the zone ends
+func (s *ZoneSchedule) FinalAnnouncedAt(start time.Time) time.Time {
+	at := start
+	for _, phase := range s.Phases[:max(len(s.Phases)-1, 0)] {
+		at = at.Add(time.Duration(phase.Warning+phase.Duration) * time.Second)
+	}
+	return at
+}
+
+func (s *ZoneSchedule) finalRadius() float64 {
+	return s.Phases[len(s.Phases)-1].Radius
+}
+
+// Centers move toward the final center as the radius shrinks
+func (s *ZoneSchedule) phaseCenter(initial Circle, i int) Point {
+	if s.FinalCenter == nil {
+		return initial.Center
+	}
+	progress := (initial.Radius - s.Phases[i].Radius) / (initial.Radius - s.finalRadius())
+	return Point{
+		Lat:  initial.Center.Lat + (s.FinalCenter.Lat-initial.Center.Lat)*progress,
+		Long: initial.Center.Long + (s.FinalCenter.Long-initial.Center.Long)*progress,
+	}
+}
+
+func interpolate(from, to Circle, progress float64) Circle {
+	return Circle{
+		Center: Point{
+			Lat:  from.Center.Lat + (to.Center.Lat-from.Center.Lat)*progress,
+			Long: from.Center.Long + (to.Center.Long-from.Center.Long)*progress,
+		},
+		Radius: from.Radius + (to.Radius-from.Radius)*progress,
+	}
+}
@@ -0,0 +1,121 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestZoneScheduleAt(t *testing.T) {
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	initial := Circle{Center: Point{Lat: 48.85, Long: 2.35}, Radius: 1000}
+	final := Destination(initial.Center, 90, 300)
+	schedule := &ZoneSchedule{
+		FinalCenter: &final,
+		Phases: []ZonePhase{
+			{Radius: 600, Warning: 60, Duration: 120},
+			{Radius: 200, Warning: 30, Duration: 60},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		at         time.Time
+		wantRadius float64
+		wantNext   float64 // Radius of the next zone, 0 when none is announced
+	}{
+		{"first warning", start.Add(30 * time.Second), 1000, 600},
+		{"halfway through the first shrink", start.Add(120 * time.Second), 800, 600},
+		{"second warning", start.Add(190 * time.Second), 600, 200},
+		{"end of the last shrink", start.Add(270 * time.Second), 200, 0},
+		{"long after", start.Add(24 * time.Hour), 200, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := schedule.At(start, initial, tt.at)
+			if math.Abs(state.Current.Radius-tt.wantRadius) > 1e-6 {
+				t.Errorf("current radius = %.1f, want %.1f", state.Current.Radius, tt.wantRadius)
+			}
+			switch {
+			case tt.wantNext == 0 && state.Next != nil:
+				t.Errorf("next zone = %v, want none", state.Next)
+			case tt.wantNext != 0 && (state.Next == nil || state.Next.Radius != tt.wantNext):
+				t.Errorf("next zone = %v, want a radius of %.0f", state.Next, tt.wantNext)
+			}
+		})
+	}
+
+	// The last zone ends on the final center
+	last := schedule.At(start, initial, start.Add(time.Hour)).Current
+	if Distance(last.Center, final) > 0.01 {
+		t.Errorf("final zone centered %.2fm away from the final center", Distance(last.Center, final))
+	}
+}
+
+func TestZoneScheduleFinalAnnouncedAt(t *testing.T) {
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		phases []ZonePhase
+		want   time.Time
+	}{
+		{"single phase", []ZonePhase{{Radius: 100, Warning: 60, Duration: 60}}, start},
+		{"two phases", []ZonePhase{{Radius: 300, Warning: 60, Duration: 120}, {Radius: 100, Warning: 30, Duration: 30}}, start.Add(180 * time.Second)},
+		{"no phase", nil, start},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &ZoneSchedule{Phases: tt.phases}
+			if got := schedule.FinalAnnouncedAt(start); !got.Equal(tt.want) {
+				t.Errorf("FinalAnnouncedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZoneScheduleValidate(t *testing.T) {
+	initial := Circle{Center: Point{Lat: 48.85, Long: 2.35}, Radius: 1000}
+	far := Destination(initial.Center, 0, 950)
+	near := Destination(initial.Center, 0, 500)
+
+	tests := []struct {
+		name     string
+		schedule ZoneSchedule
+		wantErr  bool
+	}{
+		{"valid", ZoneSchedule{Phases: []ZonePhase{{Radius: 500}, {Radius: 100}}}, false},
+		{"no phase", ZoneSchedule{}, true},
+		{"growing zone", ZoneSchedule{Phases: []ZonePhase{{Radius: 500}, {Radius: 600}}}, true},
+		{"larger than the game", ZoneSchedule{Phases: []ZonePhase{{Radius: 1000}}}, true},
+		{"negative warning", ZoneSchedule{Phases: []ZonePhase{{Radius: 500, Warning: -1}}}, true},
+		{"final zone leaving the game", ZoneSchedule{FinalCenter: &far, Phases: []ZonePhase{{Radius: 100}}}, true},
+		{"final zone inside the game", ZoneSchedule{FinalCenter: &near, Phases: []ZonePhase{{Radius: 100}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schedule.Validate(initial); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestZoneScheduleResolveCenter(t *testing.T) {
+	initial := Circle{Center: Point{Lat: 48.85, Long: 2.35}, Radius: 1000}
+	for i := 0; i < 100; i++ {
+		schedule := &ZoneSchedule{RandomCenter: true, Phases: []ZonePhase{{Radius: 200}}}
+		schedule.ResolveCenter(initial)
+		if schedule.FinalCenter == nil {
+			t.Fatal("ResolveCenter() left the final center unset")
+		}
+		if err := schedule.Validate(initial); err != nil {
+			t.Fatalf("random final center %v is invalid: %s", *schedule.FinalCenter, err)
+		}
+	}
+
+	schedule := &ZoneSchedule{Phases: []ZonePhase{{Radius: 200}}}
+	schedule.ResolveCenter(initial)
+	if *schedule.FinalCenter != initial.Center {
+		t.Errorf("final center = %v, want the center of the game", *schedule.FinalCenter)
+	}
+}
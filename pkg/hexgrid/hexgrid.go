@@ -0,0 +1,107 @@
+// Package hexgrid tessellates a game area into pointy-top hexagonal cells.
+//
+// Cells use axial coordinates on an equirectangular projection centered on
+// the origin of the grid, which is accurate enough at the scale of a game.
+package hexgrid
+
+import (
+	"math"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+// Axial coordinates of a cell
+type Cell struct {
+	Q int `json:"q"`
+	R int `json:"r"`
+}
+
+type Grid struct {
+	Origin geo.Point
+	Size   float64 // Distance in meters between the center and a corner of a cell
+}
+
+func New(origin geo.Point, size float64) Grid {
+	return Grid{Origin: origin, Size: size}
+}
+
+// Cell containing the point
+func (g Grid) CellOf(p geo.Point) Cell {
+	x, y := g.project(p)
+	q := (math.Sqrt(3)/3*x - y/3) / g.Size
+	r := (2.0 / 3 * y) / g.Size
+	return round(q, r)
+}
+
+func (g Grid) Center(c Cell) geo.Point {
+	x := g.Size * math.Sqrt(3) * (float64(c.Q) + float64(c.R)/2)
+	y := g.Size * 3.0 / 2 * float64(c.R)
+	return g.unproject(x, y)
+}
+
+// Closed ring of the six corners of the cell
+func (g Grid) Boundary(c Cell) geo.Ring {
+	center := g.Center(c)
+	cx, cy := g.project(center)
+
+	ring := make(geo.Ring, 0, 7)
+	for i := 0; i < 6; i++ {
+		angle := math.Pi / 180 * float64(60*i-30)
+		ring = append(ring, g.unproject(cx+g.Size*math.Cos(angle), cy+g.Size*math.Sin(angle)))
+	}
+	return append(ring, ring[0])
+}
+
+// Cells whose center lies inside the circle
+func (g Grid) Cover(circle geo.Circle) []Cell {
+	center := g.CellOf(circle.Center)
+	k := int(math.Ceil(circle.Radius/(g.Size*math.Sqrt(3)))) + 1
+
+	var cells []Cell
+	for _, c := range Disk(center, k) {
+		if circle.Contains(g.Center(c)) {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+// Cells at most k steps away from the center, ordered by axial coordinates
+func Disk(center Cell, k int) []Cell {
+	var cells []Cell
+	for dq := -k; dq <= k; dq++ {
+		for dr := max(-k, -dq-k); dr <= min(k, -dq+k); dr++ {
+			cells = append(cells, Cell{Q: center.Q + dq, R: center.R + dr})
+		}
+	}
+	return cells
+}
+
+func (g Grid) metersPerDegree() (float64, float64) {
+	perDegree := math.Pi * geo.EarthRadius / 180
+	return perDegree, perDegree * math.Cos(g.Origin.Lat*math.Pi/180)
+}
+
+func (g Grid) project(p geo.Point) (float64, float64) {
+	latMeters, longMeters := g.metersPerDegree()
+	return (p.Long - g.Origin.Long) * longMeters, (p.Lat - g.Origin.Lat) * latMeters
+}
+
+func (g Grid) unproject(x, y float64) geo.Point {
+	latMeters, longMeters := g.metersPerDegree()
+	return geo.Point{Lat: g.Origin.Lat + y/latMeters, Long: g.Origin.Long + x/longMeters}
+}
+
+// Round fractional axial coordinates to the nearest cell, going through cube coordinates
+func round(q, r float64) Cell {
+	s := -q - r
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+
+	if dq > dr && dq > ds {
+		rq = -rr - rs
+	} else if dr > ds {
+		rr = -rq - rs
+	}
+	return Cell{Q: int(rq), R: int(rr)}
+}
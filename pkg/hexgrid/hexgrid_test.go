@@ -0,0 +1,116 @@
+package hexgrid
+
+import (
+	"math"
+	"testing"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+)
+
+var origin = geo.Point{Lat: 48.85, Long: 2.35}
+
+func TestCellOfCenter(t *testing.T) {
+	grid := New(origin, 25)
+	tests := []Cell{{0, 0}, {1, 0}, {0, 1}, {-3, 2}, {7, -11}, {-20, -20}}
+	for _, cell := range tests {
+		if got := grid.CellOf(grid.Center(cell)); got != cell {
+			t.Errorf("CellOf(Center(%v)) = %v", cell, got)
+		}
+	}
+}
+
+func TestCellOf(t *testing.T) {
+	grid := New(origin, 25)
+	tests := []struct {
+		name string
+		p    geo.Point
+		want Cell
+	}{
+		{"origin", origin, Cell{0, 0}},
+		{"inside the origin cell", geo.Destination(origin, 45, 20), Cell{0, 0}},
+		{"east neighbour", geo.Destination(origin, 90, 25*math.Sqrt(3)), Cell{1, 0}},
+		{"west neighbour", geo.Destination(origin, 270, 25*math.Sqrt(3)), Cell{-1, 0}},
+		{"north east neighbour", geo.Destination(origin, 30, 25*math.Sqrt(3)), Cell{0, 1}},
+		{"south west neighbour", geo.Destination(origin, 210, 25*math.Sqrt(3)), Cell{0, -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grid.CellOf(tt.p); got != tt.want {
+				t.Errorf("CellOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoundary(t *testing.T) {
+	grid := New(origin, 25)
+	for _, cell := range []Cell{{0, 0}, {4, -2}} {
+		ring := grid.Boundary(cell)
+		if len(ring) != 7 || ring[0] != ring[6] {
+			t.Fatalf("Boundary(%v) is not a closed ring of six corners: %v", cell, ring)
+		}
+		center := grid.Center(cell)
+		for _, corner := range ring[:6] {
+			if d := geo.Distance(center, corner); math.Abs(d-25) > 0.01 {
+				t.Errorf("corner of %v is %.3fm away from its center, want 25m", cell, d)
+			}
+		}
+		if !ring.Contains(center) {
+			t.Errorf("Boundary(%v) does not contain its center", cell)
+		}
+	}
+}
+
+func TestDisk(t *testing.T) {
+	tests := []struct {
+		k    int
+		want int
+	}{{0, 1}, {1, 7}, {2, 19}, {5, 91}}
+	for _, tt := range tests {
+		cells := Disk(Cell{Q: 3, R: -1}, tt.k)
+		if len(cells) != tt.want {
+			t.Errorf("Disk(k=%d) has %d cells, want %d", tt.k, len(cells), tt.want)
+		}
+		seen := map[Cell]bool{}
+		for _, c := range cells {
+			dq, dr := c.Q-3, c.R+1
+			if distance := (abs(dq) + abs(dr) + abs(dq+dr)) / 2; distance > tt.k {
+				t.Errorf("Disk(k=%d) holds %v, %d steps away", tt.k, c, distance)
+			}
+			if seen[c] {
+				t.Errorf("Disk(k=%d) holds %v twice", tt.k, c)
+			}
+			seen[c] = true
+		}
+	}
+}
+
+func TestCover(t *testing.T) {
+	grid := New(origin, 25)
+	tests := []struct {
+		name   string
+		circle geo.Circle
+		min    int
+	}{
+		{"smaller than a cell", geo.Circle{Center: origin, Radius: 5}, 1},
+		{"first ring", geo.Circle{Center: origin, Radius: 50}, 7},
+		{"off center", geo.Circle{Center: geo.Destination(origin, 135, 400), Radius: 120}, 19},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := grid.Cover(tt.circle)
+			if len(cells) < tt.min {
+				t.Errorf("Cover() returned %d cells, want at least %d", len(cells), tt.min)
+			}
+			for _, c := range cells {
+				if !tt.circle.Contains(grid.Center(c)) {
+					t.Errorf("Cover() returned %v whose center is outside the circle", c)
+				}
+			}
+		})
+	}
+}
+
+func abs(x int) int {
+	return max(x, -x)
+}
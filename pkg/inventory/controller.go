@@ -1,12 +1,14 @@
 package inventory
 
 import (
+	"errors"
 	"net/http"
 
 	"bombparty.com/bombparty-api/config"
 	"bombparty.com/bombparty-api/database/dbmodel"
 	"bombparty.com/bombparty-api/pkg/model"
 	"github.com/go-chi/render"
+	"gorm.io/gorm"
 )
 
 type InventoryConfig struct {
@@ -55,6 +57,10 @@ func (config *InventoryConfig) InitUserInventory(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if !config.checkGameAcceptsInventoryChanges(w, r, *user) {
+		return
+	}
+
 	inventories, err := config.InventoryRepository.InitUserInventory(*user)
 	if err != nil {
 		render.JSON(w, r, map[string]string{"message": "Error during the initialisation of the inventory", "error": err.Error()})
@@ -79,6 +85,21 @@ func (config *InventoryConfig) ChangeBombsAmount(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if !config.checkGameAcceptsInventoryChanges(w, r, *user) {
+		return
+	}
+
+	bombType, err := config.BombTypeRepository.FindByType(req.TypeBomb)
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && !bombType.Enabled {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, map[string]string{"message": "Unknown bomb type " + req.TypeBomb})
+		return
+	}
+	if err != nil {
+		render.JSON(w, r, map[string]string{"message": "Error during fetch", "error": err.Error()})
+		return
+	}
+
 	amount, err := config.InventoryRepository.ChangeBombsAmount(*user, req.TypeBomb, req.Amount)
 	if err != nil {
 		render.JSON(w, r, map[string]string{"message": "Error during request", "error": err.Error()})
@@ -90,6 +111,26 @@ func (config *InventoryConfig) ChangeBombsAmount(w http.ResponseWriter, r *http.
 
 }
 
+// Users outside of any game can always change their inventory
+func (config *InventoryConfig) checkGameAcceptsInventoryChanges(w http.ResponseWriter, r *http.Request, user dbmodel.UserEntry) bool {
+	game, err := config.GameRepository.FindByUserId(user.IDUser)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if err != nil {
+		render.JSON(w, r, map[string]string{"message": "Error during fetch", "error": err.Error()})
+		return false
+	}
+
+	if !game.AcceptsInventoryChanges() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"message": "Inventory is locked while the game is " + string(game.Status)})
+		return false
+	}
+
+	return true
+}
+
 func convertToResponse(inventory *dbmodel.InventoryEntry) model.InventoryElement {
 	return model.InventoryElement{
 		TypeBomb: inventory.TypeBomb,
@@ -1,36 +1,104 @@
-package model
-
-import (
-	"net/http"
-
-	"github.com/google/uuid"
-)
-
-type BombRequest struct {
-	Lat      float32   `json:"lat" binding:"required"`
-	Long     float32   `json:"long" binding:"required"`
-	TypeBomb string    `json:"type_bomb" binding:"required"`
-	IdUser   uuid.UUID `json:"id_user" binding:"required"`
-}
-
-func (b *BombRequest) Bind(r *http.Request) error {
-	return nil
-}
-
-type BombUpdateRequest struct {
-	Lat      *float32 `json:"lat,omitempty"`
-	Long     *float32 `json:"long,omitempty"`
-	TypeBomb *string  `json:"type_bomb,omitempty"`
-}
-
-func (b *BombUpdateRequest) Bind(r *http.Request) error {
-	return nil
-}
-
-type BombResponse struct {
-	BombId   int       `json:"bomb_id"`
-	Lat      float32   `json:"lat"`
-	Long     float32   `json:"long"`
-	TypeBomb string    `json:"type_bomb"`
-	IdUser   uuid.UUID `json:"id_user"`
-}
+package model
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BombRequest struct {
+	Lat      float32   `json:"lat" binding:"required"`
+	Long     float32   `json:"long" binding:"required"`
+	TypeBomb string    `json:"type_bomb" binding:"required"`
+	IDGame   uuid.UUID `json:"id_game" binding:"required"`
+	IdUser   uuid.UUID `json:"id_user"`        // Optional, must be the authenticated user
+	Fuse     *int      `json:"fuse,omitempty"` // Seconds, defaults to the fuse of the bomb type
+}
+
+func (b *BombRequest) Bind(r *http.Request) error {
+	if b.IDGame == uuid.Nil {
+		return errors.New("id_game is required")
+	}
+	if b.Fuse != nil && (*b.Fuse < 5 || *b.Fuse > 3600) {
+		return errors.New("Wrong fuse value, must be between 5 and 3600 seconds")
+	}
+	return nil
+}
+
+type BombUpdateRequest struct {
+	Lat      *float32 `json:"lat,omitempty"`
+	Long     *float32 `json:"long,omitempty"`
+	TypeBomb *string  `json:"type_bomb,omitempty"` // Only accepted when unchanged, placed bombs keep their type
+}
+
+func (b *BombUpdateRequest) Bind(r *http.Request) error {
+	return nil
+}
+
+type BombResponse struct {
+	BombId     int        `json:"bomb_id"`
+	Lat        float32    `json:"lat"`
+	Long       float32    `json:"long"`
+	TypeBomb   string     `json:"type_bomb"`
+	IdUser     uuid.UUID  `json:"id_user"`
+	IDGame     uuid.UUID  `json:"id_game"`
+	IDTeam     uuid.UUID  `json:"id_team"`
+	PlacedAt   time.Time  `json:"placed_at"`
+	Fuse       int        `json:"fuse"`
+	DetonateAt time.Time  `json:"detonate_at"`
+	Status     string     `json:"status"`
+	DefusedBy  *uuid.UUID `json:"defused_by,omitempty"`
+	DefusedAt  *time.Time `json:"defused_at,omitempty"`
+
+	Behaviour     string  `json:"behaviour"`
+	TriggerRadius float64 `json:"trigger_radius,omitempty"` // Meters, mines only
+}
+
+type NearbyBombResponse struct {
+	BombResponse
+	Distance float64 `json:"distance"` // Meters from the requested point
+}
+
+// Bomb refused by the limits of the game, the client can count down to RetryAt
+type ThrottleResponse struct {
+	Error      string    `json:"error"`
+	Reason     string    `json:"reason"`
+	RetryAt    time.Time `json:"retry_at"`
+	RetryAfter int       `json:"retry_after"` // Seconds, rounded up
+}
+
+type DefusalResponse struct {
+	BombId      int        `json:"bomb_id"`
+	IDUser      uuid.UUID  `json:"id_user"`
+	IDTeam      uuid.UUID  `json:"id_team"`
+	StartedAt   time.Time  `json:"started_at"`
+	ReadyAt     time.Time  `json:"ready_at"` // Earliest moment the defusal can be confirmed
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	Points      int        `json:"points"`
+}
+
+type DetonationResponse struct {
+	BombId      int        `json:"bomb_id"`
+	IDGame      *uuid.UUID `json:"id_game"`
+	IDTeam      *uuid.UUID `json:"id_team"`
+	DetonatedAt time.Time  `json:"detonated_at"`
+	Lat         float32    `json:"lat"`
+	Long        float32    `json:"long"`
+	Radius      float64    `json:"radius"`
+	Damage      int        `json:"damage"`
+	Points      int        `json:"points"`
+	Hits        int        `json:"hits"`
+	IDChain     int        `json:"id_chain"`     // Bomb that started the chain reaction
+	TriggeredBy *int       `json:"triggered_by"` // Bomb whose blast set this one off
+	Hop         int        `json:"hop"`
+	TrippedBy   *uuid.UUID `json:"tripped_by,omitempty"` // Player who set off the mine
+}
+
+// Detonations of a chain reaction in the order they happened
+type ChainResponse struct {
+	IDChain     int                   `json:"id_chain"`
+	Points      int                   `json:"points"`
+	Detonations []*DetonationResponse `json:"detonations"`
+}
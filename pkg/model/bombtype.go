@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+var bombTypePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+type BombTypeRequest struct {
+	TypeBomb string  `json:"type_bomb"` // Only read on creation, it can't change afterwards
+	Name     string  `json:"name"`
+	Radius   float64 `json:"radius"` // Meters
+	Damage   int     `json:"damage"`
+	Fuse     int     `json:"fuse"` // Seconds
+	Rarity   string  `json:"rarity"`
+	Enabled  *bool   `json:"enabled"` // Enabled when missing
+
+	Behaviour     string  `json:"behaviour"`      // timed or mine, timed when missing
+	TriggerRadius float64 `json:"trigger_radius"` // Meters, mines only
+}
+
+func (b *BombTypeRequest) Bind(r *http.Request) error {
+	if b.Name == "" || len(b.Name) > 64 {
+		return errors.New("The name must not be empty nor longer than 64 characters")
+	}
+	if b.Radius < 1 || b.Radius > 1000 {
+		return errors.New("Wrong radius value, must be between 1 and 1000 meters")
+	}
+	if b.Damage < 0 || b.Damage > 1000 {
+		return errors.New("Wrong damage value, must be between 0 and 1000")
+	}
+	if b.Fuse < 5 || b.Fuse > 3600 {
+		return errors.New("Wrong fuse value, must be between 5 and 3600 seconds")
+	}
+	if b.Rarity != "common" && b.Rarity != "rare" && b.Rarity != "epic" && b.Rarity != "legendary" {
+		return errors.New("Wrong rarity value, must be common, rare, epic or legendary")
+	}
+	switch b.Behaviour {
+	case "", "timed":
+		if b.TriggerRadius != 0 {
+			return errors.New("Only mines have a trigger radius")
+		}
+	case "mine":
+		if b.TriggerRadius < 1 || b.TriggerRadius > 50 || b.TriggerRadius > b.Radius {
+			return errors.New("Wrong trigger_radius value, must be between 1 and 50 meters and within the radius")
+		}
+	default:
+		return errors.New("Wrong behaviour value, must be timed or mine")
+	}
+	return nil
+}
+
+func (b *BombTypeRequest) ValidateCreate() error {
+	if !bombTypePattern.MatchString(b.TypeBomb) {
+		return errors.New("Wrong type_bomb value, must be 1 to 32 lowercase letters, digits, - or _")
+	}
+	return nil
+}
+
+type BombTypeResponse struct {
+	TypeBomb  string  `json:"type_bomb"`
+	Name      string  `json:"name"`
+	Radius    float64 `json:"radius"`
+	Damage    int     `json:"damage"`
+	Fuse      int     `json:"fuse"`
+	Rarity    string  `json:"rarity"`
+	Enabled   bool    `json:"enabled"`
+	Behaviour string  `json:"behaviour"`
+
+	TriggerRadius float64   `json:"trigger_radius"` // Meters, mines only
+	UpdatedAt     time.Time `json:"updated_at"`
+}
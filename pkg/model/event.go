@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GameEventResponse struct {
+	ID      uint                   `json:"id"`
+	IDGame  uuid.UUID              `json:"id_game"`
+	Type    string                 `json:"type"`
+	IDActor *uuid.UUID             `json:"id_actor"` // Null for events of the server
+	At      time.Time              `json:"at"`
+	Data    map[string]interface{} `json:"data"`
+}
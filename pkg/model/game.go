@@ -5,6 +5,12 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/pkg/balance"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/rules"
 )
 
 type GameRequest struct {
@@ -13,6 +19,16 @@ type GameRequest struct {
 	Size            *float32   `json:"size"`
 	StartingDate    *time.Time `json:"starting_date"`
 	EndingDate      *time.Time `json:"ending_date"`
+	Mode            *string    `json:"mode"`
+	BalanceStrategy *string    `json:"balance_strategy"`
+	Visibility      *string    `json:"visibility"` // public, unlisted or private, public by default
+
+	// GeoJSON Polygon or MultiPolygon geometries
+	Boundary       *geo.MultiPolygon  `json:"boundary"`
+	ExclusionZones []geo.MultiPolygon `json:"exclusion_zones"`
+
+	ZoneSchedule *geo.ZoneSchedule `json:"zone_schedule"`
+	Ruleset      *rules.Ruleset    `json:"ruleset"` // Classic rules when missing
 }
 
 func (a *GameRequest) Bind(r *http.Request) error {
@@ -38,6 +54,30 @@ func (a *GameRequest) Bind(r *http.Request) error {
 		}
 	}
 
+	if a.Mode != nil {
+		if *a.Mode != "classic" && *a.Mode != "territory" {
+			return errors.New("Wrong mode value, must be classic or territory")
+		}
+	}
+
+	if a.BalanceStrategy != nil {
+		if !balance.Exists(*a.BalanceStrategy) {
+			return errors.New("Unknown balance strategy " + *a.BalanceStrategy)
+		}
+	}
+
+	if a.Visibility != nil {
+		if *a.Visibility != "public" && *a.Visibility != "unlisted" && *a.Visibility != "private" {
+			return errors.New("Wrong visibility value, must be public, unlisted or private")
+		}
+	}
+
+	if a.Ruleset != nil {
+		if err := a.Ruleset.Validate(); err != nil {
+			return errors.New("Wrong ruleset, " + err.Error())
+		}
+	}
+
 	if a.EndingDate != nil {
 		if a.EndingDate.After(maxLimit) || a.EndingDate.Before(minLimit) {
 			return errors.New("Wrong ending date value, must be between 1 days and 1 month")
@@ -81,17 +121,123 @@ func (a *GameRequest) ValidateUpdate() error {
 		a.CenterLongitude == nil &&
 		a.Size == nil &&
 		a.StartingDate == nil &&
-		a.EndingDate == nil {
+		a.EndingDate == nil &&
+		a.Mode == nil &&
+		a.BalanceStrategy == nil &&
+		a.Visibility == nil &&
+		a.Boundary == nil &&
+		a.ExclusionZones == nil &&
+		a.ZoneSchedule == nil &&
+		a.Ruleset == nil {
 		return errors.New("At least one field must be provided")
 	}
 	return nil
 }
 
 type GameResponse struct {
-	CenterLatitude  float32         `json:"center_latitude"`
-	CenterLongitude float32         `json:"center_longitude"`
-	Size            float32         `json:"size"`
-	StartingDate    time.Time       `json:"starting_date"`
-	EndingDate      time.Time       `json:"ending_date"`
-	Teams           []*TeamResponse `json:"teams"`
+	IDGame          uuid.UUID          `json:"id_game"`
+	Status          string             `json:"status"`
+	Mode            string             `json:"mode"`
+	JoinCode        string             `json:"join_code"`
+	IDHost          uuid.UUID          `json:"id_host"`
+	BalanceStrategy string             `json:"balance_strategy"`
+	Visibility      string             `json:"visibility"`
+	CenterLatitude  float32            `json:"center_latitude"`
+	CenterLongitude float32            `json:"center_longitude"`
+	Size            float32            `json:"size"`
+	StartingDate    time.Time          `json:"starting_date"`
+	EndingDate      time.Time          `json:"ending_date"`
+	Boundary        *geo.MultiPolygon  `json:"boundary,omitempty"`
+	ExclusionZones  []geo.MultiPolygon `json:"exclusion_zones"`
+	ZoneSchedule    *geo.ZoneSchedule  `json:"zone_schedule,omitempty"`
+	Ruleset         rules.Ruleset      `json:"ruleset"`
+	Teams           []*TeamResponse    `json:"teams"`
+}
+
+type NearbyGameResponse struct {
+	GameResponse
+	Distance float64 `json:"distance"` // Meters to the play area, 0 inside it
+}
+
+type ZoneCircle struct {
+	CenterLatitude  float64 `json:"center_latitude"`
+	CenterLongitude float64 `json:"center_longitude"`
+	Radius          float64 `json:"radius"`
+}
+
+type NextZone struct {
+	ZoneCircle
+	ShrinkStartsAt time.Time `json:"shrink_starts_at"`
+	ShrinkEndsAt   time.Time `json:"shrink_ends_at"`
+}
+
+type ZoneResponse struct {
+	At      time.Time  `json:"at"`
+	Current ZoneCircle `json:"current"`
+	Next    *NextZone  `json:"next"`
+}
+
+type TerritoryCellProperties struct {
+	Q          int        `json:"q"`
+	R          int        `json:"r"`
+	IDTeam     *uuid.UUID `json:"id_team"`
+	Color      string     `json:"color,omitempty"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+}
+
+type TerritoryFeature struct {
+	Type       string                  `json:"type"`
+	Geometry   geo.MultiPolygon        `json:"geometry"`
+	Properties TerritoryCellProperties `json:"properties"`
+}
+
+// GeoJSON FeatureCollection of the cells of a territory game
+type TerritoryResponse struct {
+	Type     string             `json:"type"`
+	Features []TerritoryFeature `json:"features"`
+}
+
+type JoinRequest struct {
+	Code   string     `json:"code"`
+	IDTeam *uuid.UUID `json:"id_team"` // Optional, picked by the balance strategy of the game by default
+	Party  string     `json:"party"`   // Optional, players of the same party are kept together
+}
+
+func (j *JoinRequest) Bind(r *http.Request) error {
+	j.Code = strings.ToUpper(strings.TrimSpace(j.Code))
+	if j.Code == "" {
+		return errors.New("The code must not be null")
+	}
+	j.Party = strings.TrimSpace(j.Party)
+	if len(j.Party) > 32 {
+		return errors.New("The party must not be longer than 32 characters")
+	}
+	return nil
+}
+
+type LobbyPlayer struct {
+	IDUser   uuid.UUID `json:"id_user"`
+	UserName string    `json:"username"`
+	Skill    int       `json:"skill"`
+	Party    string    `json:"party,omitempty"`
+}
+
+type LobbyTeam struct {
+	IDTeam  uuid.UUID     `json:"id_team"`
+	Name    string        `json:"name"`
+	Color   string        `json:"color"`
+	Players []LobbyPlayer `json:"players"`
+}
+
+type LobbyResponse struct {
+	IDGame       uuid.UUID   `json:"id_game"`
+	Status       string      `json:"status"`
+	JoinCode     string      `json:"join_code"`
+	IDHost       uuid.UUID   `json:"id_host"`
+	StartingDate time.Time   `json:"starting_date"`
+	Teams        []LobbyTeam `json:"teams"`
+}
+
+type JoinCodeResponse struct {
+	JoinCode string `json:"join_code"`
 }
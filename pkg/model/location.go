@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LocationRequest struct {
+	Lat       *float32   `json:"lat"`
+	Long      *float32   `json:"long"`
+	Accuracy  float32    `json:"accuracy"`
+	Timestamp *time.Time `json:"timestamp"` // Time of the fix on the device, kept for reference only
+}
+
+// How far the timestamp of a fix may be from its reception
+const MaxLocationSkew = time.Minute
+
+func (l *LocationRequest) Bind(r *http.Request) error {
+	if l.Lat == nil || l.Long == nil {
+		return errors.New("lat and long are required")
+	}
+	if *l.Lat < -90 || *l.Lat > 90 {
+		return errors.New("Wrong latitude value, must be between -90 / 90")
+	}
+	if *l.Long < -180 || *l.Long > 180 {
+		return errors.New("Wrong longitude value, must be between -180 / 180")
+	}
+	if l.Accuracy < 0 {
+		return errors.New("Wrong accuracy value, must not be negative")
+	}
+	if l.Timestamp != nil {
+		now := time.Now()
+		if l.Timestamp.After(now.Add(MaxLocationSkew)) {
+			return errors.New("Wrong timestamp value, must not be in the future")
+		}
+		if l.Timestamp.Before(now.Add(-MaxLocationSkew)) {
+			return errors.New("Wrong timestamp value, the location is too old")
+		}
+	}
+	return nil
+}
+
+type LocationResponse struct {
+	IDUser     uuid.UUID  `json:"id_user"`
+	IDGame     uuid.UUID  `json:"id_game"`
+	Lat        float32    `json:"lat"`
+	Long       float32    `json:"long"`
+	Accuracy   float32    `json:"accuracy"`
+	RecordedAt time.Time  `json:"recorded_at"`
+	ReportedAt *time.Time `json:"reported_at,omitempty"` // Timestamp sent by the device
+
+	TrippedMines []int `json:"tripped_mines,omitempty"` // Mines of opponents set off by this location
+}
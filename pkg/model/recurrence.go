@@ -0,0 +1,79 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecurrenceRequest struct {
+	IDTemplate uuid.UUID  `json:"id_template"`
+	Frequency  string     `json:"frequency"`  // daily or weekly
+	Weekdays   []string   `json:"weekdays"`   // English day names, weekly recurrences only
+	StartTime  string     `json:"start_time"` // 24-hour "15:04"
+	Timezone   string     `json:"timezone"`   // IANA time zone, UTC by default
+	StartsOn   *time.Time `json:"starts_on"`  // Now by default
+	EndsOn     *time.Time `json:"ends_on"`
+}
+
+func (rr *RecurrenceRequest) Bind(r *http.Request) error {
+	if rr.IDTemplate == uuid.Nil {
+		return errors.New("id_template is required")
+	}
+	if rr.Frequency != "daily" && rr.Frequency != "weekly" {
+		return errors.New("Wrong frequency value, must be daily or weekly")
+	}
+	if rr.Frequency == "weekly" && len(rr.Weekdays) == 0 {
+		return errors.New("Weekly recurrences need at least one weekday")
+	}
+	for _, day := range rr.Weekdays {
+		if _, ok := ParseWeekday(day); !ok {
+			return errors.New("Wrong weekday value: " + day)
+		}
+	}
+	if _, err := time.Parse("15:04", rr.StartTime); err != nil {
+		return errors.New("Wrong start_time value, must be formatted as 15:04")
+	}
+	if rr.Timezone == "" {
+		rr.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(rr.Timezone); err != nil {
+		return errors.New("Unknown timezone " + rr.Timezone)
+	}
+	if rr.StartsOn != nil && rr.EndsOn != nil && rr.EndsOn.Before(*rr.StartsOn) {
+		return errors.New("ends_on must be after starts_on")
+	}
+	return nil
+}
+
+func ParseWeekday(day string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(weekday.String(), day) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+type RecurrenceResponse struct {
+	IDRecurrence uuid.UUID            `json:"id_recurrence"`
+	IDTemplate   uuid.UUID            `json:"id_template"`
+	Frequency    string               `json:"frequency"`
+	Weekdays     []string             `json:"weekdays"`
+	StartTime    string               `json:"start_time"`
+	Timezone     string               `json:"timezone"`
+	StartsOn     time.Time            `json:"starts_on"`
+	EndsOn       *time.Time           `json:"ends_on"`
+	Status       string               `json:"status"`
+	Upcoming     []OccurrenceResponse `json:"upcoming,omitempty"`
+}
+
+type OccurrenceResponse struct {
+	IDOccurrence uuid.UUID  `json:"id_occurrence"`
+	OccurrenceAt time.Time  `json:"occurrence_at"`
+	IDGame       *uuid.UUID `json:"id_game"`
+	Status       string     `json:"status"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ResultTeamResponse struct {
+	Rank   int       `json:"rank"`
+	IDTeam uuid.UUID `json:"id_team"`
+	Name   string    `json:"name"`
+	Color  string    `json:"color"`
+	Score  int       `json:"score"`
+}
+
+type ResultPlayerResponse struct {
+	IDUser   uuid.UUID      `json:"id_user"`
+	UserName string         `json:"user_name"`
+	IDTeam   uuid.UUID      `json:"id_team"`
+	Points   int            `json:"points"`
+	Bombs    map[string]int `json:"bombs"` // Bombs placed per type
+}
+
+type GameResultResponse struct {
+	IDGame      uuid.UUID              `json:"id_game"`
+	FinishedAt  time.Time              `json:"finished_at"`
+	Duration    int                    `json:"duration"` // Seconds the game was running, pauses excluded
+	Teams       []ResultTeamResponse   `json:"teams"`    // Best team first
+	Players     []ResultPlayerResponse `json:"players"`  // Best player first
+	BombsByType map[string]int         `json:"bombs_by_type"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Adjustment of the score of a team by an admin, to settle a dispute
+type ScoreAdjustmentRequest struct {
+	IDTeam    uuid.UUID `json:"id_team"`
+	Points    int       `json:"points"`    // Negative to remove points
+	Objective string    `json:"objective"` // Why the score is adjusted
+}
+
+func (s *ScoreAdjustmentRequest) Bind(r *http.Request) error {
+	if s.IDTeam == uuid.Nil {
+		return errors.New("id_team is required")
+	}
+	if s.Points == 0 {
+		return errors.New("points must not be 0")
+	}
+	if s.Objective == "" {
+		return errors.New("objective is required")
+	}
+	if len(s.Objective) > 255 {
+		return errors.New("objective must not exceed 255 characters")
+	}
+	return nil
+}
+
+type ScoreEventResponse struct {
+	ID        uint       `json:"id"`
+	IDGame    uuid.UUID  `json:"id_game"`
+	IDTeam    uuid.UUID  `json:"id_team"`
+	IDUser    *uuid.UUID `json:"id_user"`
+	Reason    string     `json:"reason"`
+	Points    int        `json:"points"`
+	IDBomb    *int       `json:"bomb_id"`
+	Objective string     `json:"objective"`
+	At        time.Time  `json:"at"`
+}
+
+type TeamScoreResponse struct {
+	IDTeam uuid.UUID `json:"id_team"`
+	Name   string    `json:"name"`
+	Score  int       `json:"score"`
+}
@@ -7,17 +7,14 @@ import (
 	"github.com/google/uuid"
 )
 
+// Scores are not part of the request, they only move with score events
 type TeamRequest struct {
-	Score  int       `json:"score"`
 	Name   string    `json:"name"`
 	Color  string    `json:"color"`
 	IDGame uuid.UUID `json:"id_game"`
 }
 
 func (t *TeamRequest) Bind(r *http.Request) error {
-	if t.Score < 0 {
-		return errors.New("The score must not be negative")
-	}
 	if t.Name == "" {
 
 		return errors.New("The name must not be null")
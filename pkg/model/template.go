@@ -0,0 +1,91 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+// Longest offset and duration accepted in a template, in seconds
+const maxTemplateSeconds = 31 * 24 * 60 * 60
+
+type TemplateTeamRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type GameTemplateRequest struct {
+	Name            string  `json:"name"`
+	CenterLatitude  float32 `json:"center_latitude"`
+	CenterLongitude float32 `json:"center_longitude"`
+	Size            float32 `json:"size"`
+	StartOffset     int     `json:"start_offset"` // Seconds between the creation of a game and its start
+	Duration        int     `json:"duration"`     // Seconds
+	Mode            string  `json:"mode"`
+
+	// GeoJSON Polygon or MultiPolygon geometries
+	Boundary       *geo.MultiPolygon  `json:"boundary"`
+	ExclusionZones []geo.MultiPolygon `json:"exclusion_zones"`
+
+	ZoneSchedule *geo.ZoneSchedule     `json:"zone_schedule"`
+	Teams        []TemplateTeamRequest `json:"teams"`
+	Ruleset      *rules.Ruleset        `json:"ruleset"` // Classic rules when missing
+}
+
+func (t *GameTemplateRequest) Bind(r *http.Request) error {
+	if t.Name == "" {
+		return errors.New("The name must not be null")
+	}
+	if t.CenterLatitude < -90 || t.CenterLatitude > 90 {
+		return errors.New("Wrong Center latitude value, must be betwen -90 / 90")
+	}
+	if t.CenterLongitude < -180 || t.CenterLongitude > 180 {
+		return errors.New("Wrong Center longitude value, must be between -180 / 180")
+	}
+	if t.Size < 50 || t.Size > 10107 {
+		return errors.New("Wrong size value, must be between 50 and 10107")
+	}
+	if t.StartOffset < 0 || t.StartOffset > maxTemplateSeconds {
+		return errors.New("Wrong start offset value, must be between 0 and 1 month")
+	}
+	if t.Duration <= 0 || t.Duration > maxTemplateSeconds {
+		return errors.New("Wrong duration value, must be between 1 second and 1 month")
+	}
+	if t.Mode != "" && t.Mode != "classic" && t.Mode != "territory" {
+		return errors.New("Wrong mode value, must be classic or territory")
+	}
+	for _, team := range t.Teams {
+		if team.Name == "" || team.Color == "" {
+			return errors.New("The teams must have a name and a color")
+		}
+	}
+	if t.Ruleset != nil {
+		if err := t.Ruleset.Validate(); err != nil {
+			return errors.New("Wrong ruleset, " + err.Error())
+		}
+	}
+	return nil
+}
+
+type GameTemplateResponse struct {
+	IDTemplate      uuid.UUID             `json:"id_template"`
+	IDOwner         uuid.UUID             `json:"id_owner"`
+	Name            string                `json:"name"`
+	CenterLatitude  float32               `json:"center_latitude"`
+	CenterLongitude float32               `json:"center_longitude"`
+	Size            float32               `json:"size"`
+	StartOffset     int                   `json:"start_offset"`
+	Duration        int                   `json:"duration"`
+	Mode            string                `json:"mode"`
+	Boundary        *geo.MultiPolygon     `json:"boundary,omitempty"`
+	ExclusionZones  []geo.MultiPolygon    `json:"exclusion_zones"`
+	ZoneSchedule    *geo.ZoneSchedule     `json:"zone_schedule,omitempty"`
+	Teams           []TemplateTeamRequest `json:"teams"`
+	Ruleset         rules.Ruleset         `json:"ruleset"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
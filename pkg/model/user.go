@@ -51,9 +51,23 @@ func (u *UserUpdatePayload) Bind(r *http.Request) error {
 	return nil
 }
 
+// Only the admins set the skill of the players
+type UserSkillPayload struct {
+	Skill int `json:"skill"` // Used by the skill balance strategy, 1000 by default
+}
+
+func (u *UserSkillPayload) Bind(r *http.Request) error {
+	if u.Skill < 1 || u.Skill > 3000 {
+		return errors.New("Skill must be between 1 and 3000")
+	}
+	return nil
+}
+
 type UserResponse struct {
 	IdUser   uuid.UUID `json:"id_user"`
 	Email    string    `json:"email"`
 	UserName string    `json:"user_name"`
 	IdTeam   uuid.UUID `json:"id_team"`
+	Skill    int       `json:"skill"`
+	Role     string    `json:"role"`
 }
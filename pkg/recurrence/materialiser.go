@@ -0,0 +1,86 @@
+// Package recurrence creates the games of the recurrences ahead of their start.
+package recurrence
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+)
+
+const (
+	materialiseInterval = 10 * time.Minute
+	// How long before their start the games of an occurrence are created
+	lookAhead = 14 * 24 * time.Hour
+)
+
+type Materialiser struct {
+	recurrences dbmodel.RecurrenceRepository
+	templates   dbmodel.GameTemplateRepository
+}
+
+func New(recurrences dbmodel.RecurrenceRepository, templates dbmodel.GameTemplateRepository) *Materialiser {
+	return &Materialiser{recurrences: recurrences, templates: templates}
+}
+
+// Materialise the active recurrences now and every interval
+func (m *Materialiser) Start() {
+	go func() {
+		m.materialiseAll()
+
+		ticker := time.NewTicker(materialiseInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.materialiseAll()
+		}
+	}()
+}
+
+func (m *Materialiser) materialiseAll() {
+	recurrences, err := m.recurrences.FindActive()
+	if err != nil {
+		log.Printf("Failed to fetch active recurrences: %s\n", err.Error())
+		return
+	}
+
+	for _, recurrence := range recurrences {
+		if _, err := m.Materialise(recurrence, time.Now()); err != nil {
+			log.Printf("Failed to materialise recurrence %s: %s\n", recurrence.IDRecurrence, err.Error())
+		}
+	}
+}
+
+// Create the games of the occurrences starting within the look ahead window and
+// return how many were created. Occurrences already stored, even cancelled, are skipped
+func (m *Materialiser) Materialise(recurrence *dbmodel.RecurrenceEntry, now time.Time) (int, error) {
+	if recurrence.Status != dbmodel.RecurrenceStatusActive {
+		return 0, nil
+	}
+
+	template, err := m.templates.FindById(recurrence.IDTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, at := range recurrence.Occurrences(now, now.Add(lookAhead)) {
+		game := template.InstantiateAt(at)
+		game.Status = dbmodel.GameStatusScheduled
+		game.IDHost = recurrence.IDOwner
+		if game.ZoneSchedule != nil {
+			game.ZoneSchedule.ResolveCenter(game.Circle())
+		}
+
+		occurrence := &dbmodel.OccurrenceEntry{IDRecurrence: recurrence.IDRecurrence, OccurrenceAt: at}
+		err := m.recurrences.Materialise(occurrence, game)
+		switch {
+		case errors.Is(err, dbmodel.ErrOccurrenceExists):
+		case err != nil:
+			return created, err
+		default:
+			created++
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,62 @@
+// Package result finishes the games, by hand or once their ending date has passed, and freezes their result.
+package result
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/eventlog"
+)
+
+const finishInterval = 30 * time.Second
+
+type Finisher struct {
+	games   dbmodel.GameRepository
+	results dbmodel.GameResultRepository
+	events  *eventlog.Log
+}
+
+func New(games dbmodel.GameRepository, results dbmodel.GameResultRepository, events *eventlog.Log) *Finisher {
+	return &Finisher{games: games, results: results, events: events}
+}
+
+// Finish the games past their ending date right away, then every interval
+func (f *Finisher) Start() {
+	go func() {
+		f.finishExpired(time.Now())
+
+		ticker := time.NewTicker(finishInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			f.finishExpired(now)
+		}
+	}()
+}
+
+// Finish the game and freeze its result, actor is nil when the game ends on its own
+func (f *Finisher) Finish(game *dbmodel.GameEntry, actor *uuid.UUID, at time.Time) (*dbmodel.GameResultEntry, error) {
+	result, err := f.results.Finish(game.IDGame, game.Status, at)
+	if err != nil {
+		return nil, err
+	}
+	f.events.StatusChanged(game.IDGame, game.Status, dbmodel.GameStatusFinished, actor)
+	return result, nil
+}
+
+func (f *Finisher) finishExpired(now time.Time) {
+	games, err := f.games.FindExpired(now)
+	if err != nil {
+		log.Printf("Failed to fetch expired games: %s\n", err.Error())
+		return
+	}
+
+	for _, game := range games {
+		// The game ended at its ending date, even when noticed later
+		if _, err := f.Finish(game, nil, game.EndingDate); err != nil {
+			log.Printf("Failed to finish game %s: %s\n", game.IDGame, err.Error())
+		}
+	}
+}
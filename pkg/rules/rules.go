@@ -0,0 +1,178 @@
+// Package rules holds the ruleset a game is played with.
+//
+// The zero value is the classic ruleset: every bomb type is allowed, players
+// place as many bombs as they want, bombs can be moved and score their damage.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Shortest placement cooldown of the rulesets setting one
+const MinCooldown = time.Second
+
+// Defusal settings of the rulesets leaving them at 0
+const (
+	DefaultDefuseDistance = 15 // Meters
+	DefaultDefuseTime     = 10 // Seconds
+	DefaultDefusePoints   = 5
+	DefaultChainDelay     = 500 // Milliseconds
+	DefaultRevealRadius   = 50  // Meters
+)
+
+const (
+	MaxActiveBombs    = 100
+	MaxCooldown       = 3600 // Seconds
+	MaxScorePerHit    = 1000
+	MaxDefuseDistance = 100   // Meters
+	MaxDefuseTime     = 300   // Seconds
+	MaxChainDelay     = 10000 // Milliseconds
+	MaxRevealRadius   = 1000  // Meters
+)
+
+type Ruleset struct {
+	BombTypes         []string       `json:"bomb_types"`         // Types players may place, all of them when empty
+	MaxActiveBombs    int            `json:"max_active_bombs"`   // Armed bombs a player may have at once, 0 for no limit
+	PlacementCooldown int            `json:"placement_cooldown"` // Seconds between two bombs of a player
+	TypeCooldowns     map[string]int `json:"type_cooldowns"`     // Seconds between two bombs of a player of the same type
+	FriendlyFire      bool           `json:"friendly_fire"`      // Blasts hit the teammates of the owner too
+	ScorePerHit       int            `json:"score_per_hit"`      // Points per player hit, 0 to score the damage of the bomb
+	LockBombs         bool           `json:"lock_bombs"`         // Bombs cannot be moved once placed
+	DefuseDistance    float64        `json:"defuse_distance"`    // Meters between an opponent and the bomb they defuse, 0 for the default
+	DefuseTime        int            `json:"defuse_time"`        // Seconds between starting and confirming a defusal, 0 for the default
+	DefusePoints      int            `json:"defuse_points"`      // Points for the team of the defuser, 0 for the default
+	ChainDelay        int            `json:"chain_delay"`        // Milliseconds before a blast sets off the bombs it reaches, 0 for the default
+	RevealRadius      float64        `json:"reveal_radius"`      // Meters around a player within which armed bombs of other teams show, 0 for the default
+}
+
+// Check the limits of the ruleset
+func (r *Ruleset) Validate() error {
+	for i, typeBomb := range r.BombTypes {
+		if slices.Contains(r.BombTypes[:i], typeBomb) {
+			return fmt.Errorf("bomb type %s is listed twice", typeBomb)
+		}
+	}
+	if r.MaxActiveBombs < 0 || r.MaxActiveBombs > MaxActiveBombs {
+		return fmt.Errorf("max active bombs must be between 0 and %d", MaxActiveBombs)
+	}
+	if r.PlacementCooldown < 0 || r.PlacementCooldown > MaxCooldown {
+		return fmt.Errorf("placement cooldown must be between 0 and %d seconds", MaxCooldown)
+	}
+	for typeBomb, cooldown := range r.TypeCooldowns {
+		if cooldown < 0 || cooldown > MaxCooldown {
+			return fmt.Errorf("cooldown of %s bombs must be between 0 and %d seconds", typeBomb, MaxCooldown)
+		}
+	}
+	if r.ScorePerHit < 0 || r.ScorePerHit > MaxScorePerHit {
+		return fmt.Errorf("score per hit must be between 0 and %d", MaxScorePerHit)
+	}
+	if r.DefuseDistance < 0 || r.DefuseDistance > MaxDefuseDistance {
+		return fmt.Errorf("defuse distance must be between 0 and %d meters", MaxDefuseDistance)
+	}
+	if r.DefuseTime < 0 || r.DefuseTime > MaxDefuseTime {
+		return fmt.Errorf("defuse time must be between 0 and %d seconds", MaxDefuseTime)
+	}
+	if r.DefusePoints < 0 || r.DefusePoints > MaxScorePerHit {
+		return fmt.Errorf("defuse points must be between 0 and %d", MaxScorePerHit)
+	}
+	if r.ChainDelay < 0 || r.ChainDelay > MaxChainDelay {
+		return fmt.Errorf("chain delay must be between 0 and %d milliseconds", MaxChainDelay)
+	}
+	if r.RevealRadius < 0 || r.RevealRadius > MaxRevealRadius {
+		return fmt.Errorf("reveal radius must be between 0 and %d meters", MaxRevealRadius)
+	}
+	if r.FriendlyFire && r.ScorePerHit == 0 {
+		return errors.New("friendly fire needs a score per hit")
+	}
+	return nil
+}
+
+// Check the bomb types named by the ruleset are among the registered ones
+func (r *Ruleset) CheckTypes(types []string) error {
+	for _, typeBomb := range r.BombTypes {
+		if !slices.Contains(types, typeBomb) {
+			return fmt.Errorf("unknown bomb type %s", typeBomb)
+		}
+	}
+	for typeBomb := range r.TypeCooldowns {
+		if !slices.Contains(types, typeBomb) {
+			return fmt.Errorf("unknown bomb type %s", typeBomb)
+		}
+	}
+	return nil
+}
+
+func (r *Ruleset) AllowsBomb(typeBomb string) bool {
+	return len(r.BombTypes) == 0 || slices.Contains(r.BombTypes, typeBomb)
+}
+
+// Time a player waits between two bombs, 0 when the ruleset sets no cooldown
+func (r *Ruleset) Cooldown() time.Duration {
+	if r.PlacementCooldown <= 0 {
+		return 0
+	}
+	return max(time.Duration(r.PlacementCooldown)*time.Second, MinCooldown)
+}
+
+// Time a player waits between two bombs of the type, 0 when only the placement cooldown applies
+func (r *Ruleset) TypeCooldown(typeBomb string) time.Duration {
+	return time.Duration(r.TypeCooldowns[typeBomb]) * time.Second
+}
+
+// Whether detonations score the players they hit rather than their damage
+func (r *Ruleset) ScoresHits() bool {
+	return r.ScorePerHit > 0
+}
+
+// Points of a detonation hitting the given opponents and teammates of the owner
+func (r *Ruleset) HitPoints(opponents, teammates int) int {
+	points := opponents * r.ScorePerHit
+	if r.FriendlyFire {
+		points -= teammates * r.ScorePerHit
+	}
+	return points
+}
+
+// Meters within which an opponent can defuse a bomb
+func (r *Ruleset) DefuseRange() float64 {
+	if r.DefuseDistance == 0 {
+		return DefaultDefuseDistance
+	}
+	return r.DefuseDistance
+}
+
+// Time an opponent spends defusing a bomb
+func (r *Ruleset) DefuseDuration() time.Duration {
+	if r.DefuseTime == 0 {
+		return DefaultDefuseTime * time.Second
+	}
+	return time.Duration(r.DefuseTime) * time.Second
+}
+
+func (r *Ruleset) DefuseScore() int {
+	if r.DefusePoints == 0 {
+		return DefaultDefusePoints
+	}
+	return r.DefusePoints
+}
+
+// Time between a blast and the explosion of the armed bombs it reaches
+func (r *Ruleset) ChainHop() time.Duration {
+	if r.ChainDelay == 0 {
+		return DefaultChainDelay * time.Millisecond
+	}
+	return time.Duration(r.ChainDelay) * time.Millisecond
+}
+
+// Meters within which a player sees the armed bombs of the other teams, never less than the defuse range
+// so that every bomb a player can defuse shows
+func (r *Ruleset) RevealRange() float64 {
+	radius := r.RevealRadius
+	if radius == 0 {
+		radius = DefaultRevealRadius
+	}
+	return max(radius, r.DefuseRange())
+}
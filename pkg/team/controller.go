@@ -5,6 +5,7 @@ import (
 
 	"bombparty.com/bombparty-api/config"
 	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
 	"bombparty.com/bombparty-api/pkg/model"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -21,7 +22,7 @@ func New(configuration *config.Config) *TeamConfig {
 
 // CreateTeamHandler godoc
 // @Summary      Create a team
-// @Description  Create a new team linked to a game
+// @Description  Create a new team linked to a game, only the host of the game or an admin can
 // @Tags         Teams
 // @Accept       json
 // @Produce      json
@@ -29,6 +30,9 @@ func New(configuration *config.Config) *TeamConfig {
 // @Security     BearerAuth
 // @Success      201   {object}  model.TeamResponse
 // @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /api/v1/teams [post]
 func (config *TeamConfig) CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
@@ -42,8 +46,16 @@ func (config *TeamConfig) CreateTeamHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	user, ok := config.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if _, ok := config.findManagedGame(w, r, user, req.IDGame); !ok {
+		return
+	}
+
 	team := &dbmodel.TeamEntry{
-		Score:  req.Score,
 		Name:   req.Name,
 		Color:  req.Color,
 		IDGame: req.IDGame,
@@ -72,7 +84,7 @@ func (config *TeamConfig) CreateTeamHandler(w http.ResponseWriter, r *http.Reque
 
 // GetAllTeamsHandler godoc
 // @Summary      Get all teams
-// @Description  Retrieve all teams
+// @Description  Retrieve all teams, but those of the private games hidden from the user
 // @Tags         Teams
 // @Produce      json
 // @Security     BearerAuth
@@ -80,6 +92,11 @@ func (config *TeamConfig) CreateTeamHandler(w http.ResponseWriter, r *http.Reque
 // @Failure      500  {object}  map[string]string
 // @Router       /api/v1/teams [get]
 func (config *TeamConfig) GetAllTeamsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := config.currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	teams, err := config.TeamRepository.FindAll()
 	if err != nil {
 		render.Status(r, http.StatusInternalServerError)
@@ -88,9 +105,29 @@ func (config *TeamConfig) GetAllTeamsHandler(w http.ResponseWriter, r *http.Requ
 		})
 		return
 	}
+	games, err := config.GameRepository.FindAll()
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{
+			"error": "failed to fetch team",
+		})
+		return
+	}
+
+	// Teams of private games are hidden from the outsiders
+	visible := map[uuid.UUID]bool{}
+	for _, game := range games {
+		visible[game.IDGame] = game.VisibleTo(user)
+	}
+	res := []*dbmodel.TeamEntry{}
+	for _, team := range teams {
+		if visible[team.IDGame] {
+			res = append(res, team)
+		}
+	}
 	
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, teams)
+	render.JSON(w, r, res)
 }
 
 // GetTeamByIDHandler godoc
@@ -105,23 +142,13 @@ func (config *TeamConfig) GetAllTeamsHandler(w http.ResponseWriter, r *http.Requ
 // @Failure      404  {object}  map[string]string
 // @Router       /api/v1/teams/{id} [get]
 func (config *TeamConfig) GetTeamByIDHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-
-	teamID, err := uuid.Parse(idParam)
-	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{
-			"error": "invalid UUID format",
-		})
+	user, ok := config.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	team, err := config.TeamRepository.FindById(teamID)
-	if err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, map[string]string{
-			"error": "team not found",
-		})
+	team, ok := config.findTeam(w, r, user)
+	if !ok {
 		return
 	}
 
@@ -140,7 +167,8 @@ func (config *TeamConfig) GetTeamByIDHandler(w http.ResponseWriter, r *http.Requ
 
 // UpdateTeamHandler godoc
 // @Summary      Update a team
-// @Description  Update an existing team
+// @Description  Update an existing team, only the host of the game or an admin can.
+// @Description  The team can only change game before both games start
 // @Tags         Teams
 // @Accept       json
 // @Produce      json
@@ -149,21 +177,12 @@ func (config *TeamConfig) GetTeamByIDHandler(w http.ResponseWriter, r *http.Requ
 // @Security     BearerAuth
 // @Success      200   {object}  model.TeamResponse
 // @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
 // @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /api/v1/teams/{id} [put]
 func (config *TeamConfig) UpdateTeamHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-
-	teamID, err := uuid.Parse(idParam)
-	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{
-			"error": "invalid UUID format",
-		})
-		return
-	}
-
 	req := &model.TeamRequest{}
 	if err := render.Bind(r, req); err != nil {
 		render.Status(r, http.StatusBadRequest)
@@ -173,16 +192,36 @@ func (config *TeamConfig) UpdateTeamHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	existing, err := config.TeamRepository.FindById(teamID)
-	if err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, map[string]string{
-			"error": "team not found",
-		})
+	user, ok := config.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	existing, ok := config.findTeam(w, r, user)
+	if !ok {
 		return
 	}
 
-	existing.Score = req.Score
+	game, ok := config.findManagedGame(w, r, user, existing.IDGame)
+	if !ok {
+		return
+	}
+
+	// The team can only move between games the user manages, before either of them starts
+	if req.IDGame != existing.IDGame {
+		target, ok := config.findManagedGame(w, r, user, req.IDGame)
+		if !ok {
+			return
+		}
+		if game.HasStarted() || target.HasStarted() {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{
+				"error": "game already started, the team cannot change game",
+			})
+			return
+		}
+	}
+
 	existing.Name = req.Name
 	existing.Color = req.Color
 	existing.IDGame = req.IDGame
@@ -208,37 +247,45 @@ func (config *TeamConfig) UpdateTeamHandler(w http.ResponseWriter, r *http.Reque
 
 // DeleteTeamHandler godoc
 // @Summary      Delete a team
-// @Description  Delete a team by ID
+// @Description  Delete a team by ID, only the host of the game or an admin can and only before the game starts.
+// @Description  Its players leave the team and their party
 // @Tags         Teams
 // @Produce      json
 // @Param        id   path      string  true  "Team ID (UUID)"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]string
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/v1/teams/{id} [delete]
 func (config *TeamConfig) DeleteTeamHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
+	user, ok := config.currentUser(w, r)
+	if !ok {
+		return
+	}
 
-	teamID, err := uuid.Parse(idParam)
-	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, map[string]string{
-			"error": "invalid UUID format",
-		})
+	team, ok := config.findTeam(w, r, user)
+	if !ok {
 		return
 	}
-	team, err := config.TeamRepository.FindById(teamID)
-	if err != nil {
-		render.Status(r, http.StatusNotFound)
+
+	game, ok := config.findManagedGame(w, r, user, team.IDGame)
+	if !ok {
+		return
+	}
+
+	// The players of a running game keep their team
+	if game.HasStarted() {
+		render.Status(r, http.StatusConflict)
 		render.JSON(w, r, map[string]string{
-			"error": "team not found",
+			"error": "game already started, the team cannot be deleted",
 		})
 		return
 	}
 
-	if err := config.TeamRepository.Delete(teamID, team); err != nil {
+	if err := config.TeamRepository.Delete(team.IDTeam, team); err != nil {
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{
 			"error": "failed to delete team",
@@ -251,3 +298,78 @@ func (config *TeamConfig) DeleteTeamHandler(w http.ResponseWriter, r *http.Reque
 		"message": "team deleted",
 	})
 }
+
+// Fetch the user owning the token of the request
+func (config *TeamConfig) currentUser(w http.ResponseWriter, r *http.Request) (*dbmodel.UserEntry, bool) {
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{
+			"error": "user not found",
+		})
+		return nil, false
+	}
+	return user, true
+}
+
+// Fetch the team of the URL, the teams of games hidden from the user don't exist for them
+func (config *TeamConfig) findTeam(w http.ResponseWriter, r *http.Request, user *dbmodel.UserEntry) (*dbmodel.TeamEntry, bool) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{
+			"error": "invalid UUID format",
+		})
+		return nil, false
+	}
+
+	team, err := config.TeamRepository.FindById(teamID)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "team not found",
+		})
+		return nil, false
+	}
+
+	game, err := config.GameRepository.FindById(team.IDGame)
+	if err != nil || !game.VisibleTo(user) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "team not found",
+		})
+		return nil, false
+	}
+
+	return team, true
+}
+
+// Fetch the game of a team and check the user manages it and it is not finished
+func (config *TeamConfig) findManagedGame(w http.ResponseWriter, r *http.Request, user *dbmodel.UserEntry, idGame uuid.UUID) (*dbmodel.GameEntry, bool) {
+	game, err := config.GameRepository.FindById(idGame)
+	if err != nil || !game.VisibleTo(user) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{
+			"error": "game not found",
+		})
+		return nil, false
+	}
+
+	if !game.ManagedBy(user) {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{
+			"error": "only the host of the game can change its teams",
+		})
+		return nil, false
+	}
+
+	if game.IsFinished() {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{
+			"error": "game is finished, teams cannot be changed",
+		})
+		return nil, false
+	}
+
+	return game, true
+}
@@ -0,0 +1,268 @@
+package team
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/model"
+)
+
+// Private game hosted by alice, who plays in red against bob in blue. Alice hosts another game, next,
+// and mallory hosts a public one. Eve plays in no game and root is an admin
+type teamFixture struct {
+	config  *config.Config
+	db      *gorm.DB
+	game    *dbmodel.GameEntry
+	next    *dbmodel.GameEntry
+	mallory *dbmodel.GameEntry
+	users   map[string]*dbmodel.UserEntry
+	tokens  map[string]string
+}
+
+func newTeamFixture(t *testing.T, status dbmodel.GameStatus) *teamFixture {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	configuration, err := config.NewWithDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration.JwtKey = "test"
+
+	f := &teamFixture{config: configuration, db: db, users: map[string]*dbmodel.UserEntry{}, tokens: map[string]string{}}
+	alice, mallory := uuid.New(), uuid.New()
+	f.game = f.createGame(t, alice, status, dbmodel.GameVisibilityPrivate, []dbmodel.TeamEntry{{Name: "red", Color: "#ff0000"}, {Name: "blue", Color: "#0000ff"}})
+	f.next = f.createGame(t, alice, dbmodel.GameStatusDraft, dbmodel.GameVisibilityPublic, nil)
+	f.mallory = f.createGame(t, mallory, dbmodel.GameStatusDraft, dbmodel.GameVisibilityPublic, nil)
+
+	f.addUser(t, "alice", alice, &f.game.Teams[0].IDTeam, dbmodel.UserRolePlayer)
+	f.addUser(t, "bob", uuid.New(), &f.game.Teams[1].IDTeam, dbmodel.UserRolePlayer)
+	f.addUser(t, "mallory", mallory, nil, dbmodel.UserRolePlayer)
+	f.addUser(t, "eve", uuid.New(), nil, dbmodel.UserRolePlayer)
+	f.addUser(t, "root", uuid.New(), nil, dbmodel.UserRoleAdmin)
+	return f
+}
+
+func (f *teamFixture) createGame(t *testing.T, host uuid.UUID, status dbmodel.GameStatus, visibility dbmodel.GameVisibility, teams []dbmodel.TeamEntry) *dbmodel.GameEntry {
+	t.Helper()
+	game := &dbmodel.GameEntry{
+		CenterLatitude:  48.85,
+		CenterLongitude: 2.35,
+		Size:            500,
+		StartingDate:    time.Now().Add(-time.Hour),
+		EndingDate:      time.Now().Add(time.Hour),
+		Status:          status,
+		Visibility:      visibility,
+		IDHost:          host,
+		Teams:           teams,
+	}
+	f.create(t, game)
+	return game
+}
+
+func (f *teamFixture) addUser(t *testing.T, name string, id uuid.UUID, team *uuid.UUID, role dbmodel.UserRole) {
+	t.Helper()
+	user := &dbmodel.UserEntry{IDUser: id, UserName: name, Email: name + "@bombparty.com", IDTeam: team, Role: role}
+	f.create(t, user)
+	token, err := authentication.GenerateToken(f.config.JwtKey, user.Email, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.users[name], f.tokens[name] = user, token
+}
+
+func (f *teamFixture) create(t *testing.T, value any) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Send the request of the user to the team routes, body is encoded as JSON unless nil
+func (f *teamFixture) do(t *testing.T, name, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := httptest.NewRequest(method, path, &payload)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+f.tokens[name])
+	w := httptest.NewRecorder()
+	Routes(f.config).ServeHTTP(w, r)
+	return w
+}
+
+func TestGetTeamHandlers(t *testing.T) {
+	tests := []struct {
+		name   string
+		viewer string
+		want   int // Status of the team of the private game
+	}{
+		{"host", "alice", http.StatusOK},
+		{"player", "bob", http.StatusOK},
+		{"admin", "root", http.StatusOK},
+		{"outsider of the private game", "eve", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTeamFixture(t, dbmodel.GameStatusRunning)
+			f.create(t, &dbmodel.TeamEntry{Name: "green", Color: "#00ff00", IDGame: f.mallory.IDGame})
+
+			w := f.do(t, tt.viewer, http.MethodGet, "/"+f.game.Teams[0].IDTeam.String(), nil)
+			if w.Code != tt.want {
+				t.Errorf("get status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+
+			w = f.do(t, tt.viewer, http.MethodGet, "/", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("list status = %d: %s", w.Code, w.Body.String())
+			}
+			var teams []model.TeamResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &teams); err != nil {
+				t.Fatal(err)
+			}
+			want := 3
+			if tt.want == http.StatusNotFound {
+				want = 1
+			}
+			if len(teams) != want {
+				t.Errorf("listed %d teams, want %d", len(teams), want)
+			}
+		})
+	}
+}
+
+func TestChangeTeamHandlers(t *testing.T) {
+	tests := []struct {
+		name   string
+		status dbmodel.GameStatus
+		user   string
+		method string
+		target func(t *testing.T, f *teamFixture) *dbmodel.GameEntry // Game of the payload, the private game if nil
+		want   int
+		moved  bool // Whether the red team ends up in the target game
+	}{
+		{"host creates", dbmodel.GameStatusDraft, "alice", http.MethodPost, nil, http.StatusCreated, false},
+		{"admin creates", dbmodel.GameStatusDraft, "root", http.MethodPost, nil, http.StatusCreated, false},
+		{"player creates", dbmodel.GameStatusDraft, "bob", http.MethodPost, nil, http.StatusForbidden, false},
+		{"outsider creates", dbmodel.GameStatusDraft, "eve", http.MethodPost, nil, http.StatusNotFound, false},
+		{"host creates in a finished game", dbmodel.GameStatusFinished, "alice", http.MethodPost, nil, http.StatusConflict, false},
+		{"host renames", dbmodel.GameStatusRunning, "alice", http.MethodPut, nil, http.StatusOK, false},
+		{"player renames", dbmodel.GameStatusDraft, "bob", http.MethodPut, nil, http.StatusForbidden, false},
+		{"outsider renames", dbmodel.GameStatusDraft, "eve", http.MethodPut, nil, http.StatusNotFound, false},
+		{"host moves to their other game", dbmodel.GameStatusDraft, "alice", http.MethodPut, func(t *testing.T, f *teamFixture) *dbmodel.GameEntry { return f.next }, http.StatusOK, true},
+		{"host moves to the game of another host", dbmodel.GameStatusDraft, "alice", http.MethodPut, func(t *testing.T, f *teamFixture) *dbmodel.GameEntry { return f.mallory }, http.StatusForbidden, false},
+		{"other host takes the team", dbmodel.GameStatusDraft, "mallory", http.MethodPut, func(t *testing.T, f *teamFixture) *dbmodel.GameEntry { return f.mallory }, http.StatusNotFound, false},
+		{"host moves after the start", dbmodel.GameStatusRunning, "alice", http.MethodPut, func(t *testing.T, f *teamFixture) *dbmodel.GameEntry { return f.next }, http.StatusConflict, false},
+		{"admin moves to a started game", dbmodel.GameStatusDraft, "root", http.MethodPut, func(t *testing.T, f *teamFixture) *dbmodel.GameEntry {
+			f.next.Status = dbmodel.GameStatusRunning
+			if err := f.db.Model(f.next).Update("status", f.next.Status).Error; err != nil {
+				t.Fatal(err)
+			}
+			return f.next
+		}, http.StatusConflict, false},
+		{"player deletes", dbmodel.GameStatusDraft, "bob", http.MethodDelete, nil, http.StatusForbidden, false},
+		{"outsider deletes", dbmodel.GameStatusDraft, "eve", http.MethodDelete, nil, http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTeamFixture(t, tt.status)
+			target := f.game
+			if tt.target != nil {
+				target = tt.target(t, f)
+			}
+
+			red := f.game.Teams[0].IDTeam
+			path, body := "/"+red.String(), any(model.TeamRequest{Name: "crimson", Color: "#dc143c", IDGame: target.IDGame})
+			switch tt.method {
+			case http.MethodPost:
+				path = "/"
+			case http.MethodDelete:
+				body = nil
+			}
+			w := f.do(t, tt.user, tt.method, path, body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+
+			// The red team is only changed by a successful update
+			team, err := f.config.TeamRepository.FindById(red)
+			if err != nil {
+				t.Fatalf("red team lost: %s", err)
+			}
+			renamed := tt.method == http.MethodPut && tt.want == http.StatusOK
+			if (team.Name == "crimson") != renamed {
+				t.Errorf("red team named %s, renamed = %t", team.Name, renamed)
+			}
+			if moved := team.IDGame == target.IDGame && target != f.game; moved != tt.moved {
+				t.Errorf("red team in game %s, moved = %t", team.IDGame, tt.moved)
+			}
+		})
+	}
+}
+
+func TestDeleteTeamHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		status dbmodel.GameStatus
+		want   int
+	}{
+		{"draft game", dbmodel.GameStatusDraft, http.StatusOK},
+		{"scheduled game", dbmodel.GameStatusScheduled, http.StatusOK},
+		{"running game", dbmodel.GameStatusRunning, http.StatusConflict},
+		{"paused game", dbmodel.GameStatusPaused, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTeamFixture(t, tt.status)
+			for _, name := range []string{"alice", "bob"} {
+				if err := f.db.Model(f.users[name]).Update("party", "friends").Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			red := f.game.Teams[0].IDTeam
+			w := f.do(t, "alice", http.MethodDelete, "/"+red.String(), nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			deleted := tt.want == http.StatusOK
+			if _, err := f.config.TeamRepository.FindById(red); (err != nil) != deleted {
+				t.Errorf("red team found = %t, want %t", err == nil, !deleted)
+			}
+
+			// The players of the deleted team leave it and their party, the others stay
+			for name, released := range map[string]bool{"alice": deleted, "bob": false} {
+				user, err := f.config.UserRepository.FindOne("email", f.users[name].Email)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := user.IDTeam == nil && user.Party == ""; got != released {
+					t.Errorf("%s in team %v and party %q, released = %t", name, user.IDTeam, user.Party, released)
+				}
+			}
+		})
+	}
+}
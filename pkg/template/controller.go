@@ -0,0 +1,297 @@
+package template
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/authentication"
+	"bombparty.com/bombparty-api/pkg/geo"
+	"bombparty.com/bombparty-api/pkg/model"
+	"bombparty.com/bombparty-api/pkg/rules"
+)
+
+type TemplateConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *TemplateConfig {
+	return &TemplateConfig{configuration}
+}
+
+// PostHandler godoc
+// @Summary      Create a game template
+// @Description  Stores a reusable game setup owned by the authenticated user
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        template  body      model.GameTemplateRequest  true  "Template creation payload"
+// @Security     BearerAuth
+// @Success      201  {object}  model.GameTemplateResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload"
+// @Failure      500  {object}  map[string]string  "Failed to create template"
+// @Router       /api/v1/templates [post]
+func (config *TemplateConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, map[string]string{"Error": "User not found"})
+		return
+	}
+
+	req := &model.GameTemplateRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Template request payload. " + err.Error()})
+		return
+	}
+
+	entry := &dbmodel.GameTemplateEntry{IDOwner: user.IDUser}
+	if err := config.fillTemplate(entry, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+
+	entry, err = config.TemplateRepository.Create(entry)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Create Template"})
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, convertToResponse(entry))
+}
+
+// GetAllHandler godoc
+// @Summary      Get all game templates
+// @Description  Retrieves every game template, sorted by name
+// @Tags         templates
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   model.GameTemplateResponse
+// @Failure      500  {object}  map[string]string  "Failed to retrieve templates"
+// @Router       /api/v1/templates [get]
+func (config *TemplateConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	entries, err := config.TemplateRepository.FindAll()
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Find Templates"})
+		return
+	}
+
+	res := []*model.GameTemplateResponse{}
+	for _, entry := range entries {
+		res = append(res, convertToResponse(entry))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// GetByIdHandler godoc
+// @Summary      Get a game template
+// @Description  Retrieves a game template by its ID
+// @Tags         templates
+// @Produce      json
+// @Param        id   path      string  true  "Template ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameTemplateResponse
+// @Failure      400  {object}  map[string]string  "Invalid Id"
+// @Failure      404  {object}  map[string]string  "Template not found"
+// @Router       /api/v1/templates/{id} [get]
+func (config *TemplateConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	render.JSON(w, r, convertToResponse(entry))
+}
+
+// UpdateHandler godoc
+// @Summary      Update a game template
+// @Description  Replaces the setup of a game template, only its owner can update it
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                     true  "Template ID"
+// @Param        template  body      model.GameTemplateRequest  true  "Template payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.GameTemplateResponse
+// @Failure      400  {object}  map[string]string  "Invalid request payload"
+// @Failure      403  {object}  map[string]string  "Not the owner of the template"
+// @Failure      404  {object}  map[string]string  "Template not found"
+// @Failure      500  {object}  map[string]string  "Failed to update template"
+// @Router       /api/v1/templates/{id} [put]
+func (config *TemplateConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findOwnedTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	req := &model.GameTemplateRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Template request payload. " + err.Error()})
+		return
+	}
+
+	if err := config.fillTemplate(entry, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": err.Error()})
+		return
+	}
+
+	entry, err := config.TemplateRepository.Update(entry)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Update Template"})
+		return
+	}
+
+	render.JSON(w, r, convertToResponse(entry))
+}
+
+// DeleteHandler godoc
+// @Summary      Delete a game template
+// @Description  Deletes a game template, the games created from it are kept
+// @Tags         templates
+// @Produce      json
+// @Param        id   path      string  true  "Template ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Template deleted successfully"
+// @Failure      403  {object}  map[string]string  "Not the owner of the template"
+// @Failure      404  {object}  map[string]string  "Template not found"
+// @Failure      500  {object}  map[string]string  "Failed to delete template"
+// @Router       /api/v1/templates/{id} [delete]
+func (config *TemplateConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+
+	entry, ok := config.findOwnedTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := config.TemplateRepository.DeleteById(entry.IDTemplate); err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"Error": "Failed to Delete Template"})
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Template deleted successfully"})
+}
+
+func (config *TemplateConfig) findTemplate(w http.ResponseWriter, r *http.Request) (*dbmodel.GameTemplateEntry, bool) {
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"Error": "Invalid Id"})
+		return nil, false
+	}
+
+	entry, err := config.TemplateRepository.FindById(id)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"Error": "Template not found in the DB"})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Fetch the template of the URL, only if the authenticated user owns it
+func (config *TemplateConfig) findOwnedTemplate(w http.ResponseWriter, r *http.Request) (*dbmodel.GameTemplateEntry, bool) {
+
+	entry, ok := config.findTemplate(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := authentication.CurrentUser(r, config.UserRepository)
+	if err != nil || user.IDUser != entry.IDOwner {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"Error": "Only the owner of the template can change it"})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Copy the request into the template, check its ruleset names registered bomb types
+// and its zone schedule fits the game circle
+func (config *TemplateConfig) fillTemplate(entry *dbmodel.GameTemplateEntry, req *model.GameTemplateRequest) error {
+
+	entry.Name = req.Name
+	entry.CenterLatitude = req.CenterLatitude
+	entry.CenterLongitude = req.CenterLongitude
+	entry.Size = req.Size
+	entry.StartOffset = req.StartOffset
+	entry.Duration = req.Duration
+	entry.Mode = dbmodel.GameMode(req.Mode)
+	if entry.Mode == "" {
+		entry.Mode = dbmodel.GameModeClassic
+	}
+	entry.Boundary = req.Boundary
+	entry.ExclusionZones = req.ExclusionZones
+	entry.ZoneSchedule = req.ZoneSchedule
+	entry.Ruleset = rules.Ruleset{}
+	if req.Ruleset != nil {
+		types, err := config.BombTypeRepository.Types()
+		if err != nil {
+			return err
+		}
+		if err := req.Ruleset.CheckTypes(types); err != nil {
+			return err
+		}
+		entry.Ruleset = *req.Ruleset
+	}
+
+	entry.Teams = []dbmodel.TemplateTeam{}
+	for _, team := range req.Teams {
+		entry.Teams = append(entry.Teams, dbmodel.TemplateTeam{Name: team.Name, Color: team.Color})
+	}
+
+	if entry.ZoneSchedule != nil {
+		circle := geo.Circle{
+			Center: geo.NewPoint(entry.CenterLatitude, entry.CenterLongitude),
+			Radius: float64(entry.Size),
+		}
+		return entry.ZoneSchedule.Validate(circle)
+	}
+	return nil
+}
+
+func convertToResponse(entry *dbmodel.GameTemplateEntry) *model.GameTemplateResponse {
+
+	res := &model.GameTemplateResponse{
+		IDTemplate:      entry.IDTemplate,
+		IDOwner:         entry.IDOwner,
+		Name:            entry.Name,
+		CenterLatitude:  entry.CenterLatitude,
+		CenterLongitude: entry.CenterLongitude,
+		Size:            entry.Size,
+		StartOffset:     entry.StartOffset,
+		Duration:        entry.Duration,
+		Mode:            string(entry.Mode),
+		Boundary:        entry.Boundary,
+		ExclusionZones:  entry.ExclusionZones,
+		ZoneSchedule:    entry.ZoneSchedule,
+		Teams:           []model.TemplateTeamRequest{},
+		Ruleset:         entry.Ruleset,
+		UpdatedAt:       entry.UpdatedAt,
+	}
+	for _, team := range entry.Teams {
+		res.Teams = append(res.Teams, model.TemplateTeamRequest{Name: team.Name, Color: team.Color})
+	}
+
+	return res
+}
@@ -0,0 +1,28 @@
+package template
+
+import (
+	"bombparty.com/bombparty-api/config"
+	"bombparty.com/bombparty-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init Router
+	templateConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(configuration.JwtKey))
+
+		router.Get("/", templateConfig.GetAllHandler)
+		router.Get("/{id}", templateConfig.GetByIdHandler)
+		router.Post("/", templateConfig.PostHandler)
+		router.Put("/{id}", templateConfig.UpdateHandler)
+		router.Delete("/{id}", templateConfig.DeleteHandler)
+	})
+
+	return router
+}
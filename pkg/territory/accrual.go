@@ -0,0 +1,75 @@
+// Package territory makes the teams of territory games earn points for the cells they hold.
+package territory
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"bombparty.com/bombparty-api/database/dbmodel"
+	"bombparty.com/bombparty-api/pkg/eventlog"
+)
+
+const (
+	accrualInterval = time.Minute
+	pointsPerCell   = 1
+)
+
+type Accrual struct {
+	games     dbmodel.GameRepository
+	territory dbmodel.TerritoryRepository
+	scores    dbmodel.ScoreRepository
+	events    *eventlog.Log
+}
+
+func NewAccrual(games dbmodel.GameRepository, territory dbmodel.TerritoryRepository, scores dbmodel.ScoreRepository,
+	events *eventlog.Log) *Accrual {
+	return &Accrual{games: games, territory: territory, scores: scores, events: events}
+}
+
+// Credit the teams of the running territory games every interval
+func (a *Accrual) Start() {
+	go func() {
+		ticker := time.NewTicker(accrualInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			a.accrue()
+		}
+	}()
+}
+
+func (a *Accrual) accrue() {
+	games, err := a.games.FindByStatus(dbmodel.GameStatusRunning)
+	if err != nil {
+		log.Printf("Failed to fetch running games: %s\n", err.Error())
+		return
+	}
+
+	for _, game := range games {
+		if game.Mode != dbmodel.GameModeTerritory {
+			continue
+		}
+
+		counts, err := a.territory.CountByTeam(game.IDGame)
+		if err != nil {
+			log.Printf("Failed to count territory of game %s: %s\n", game.IDGame, err.Error())
+			continue
+		}
+		now := time.Now()
+		for idTeam, cells := range counts {
+			score := &dbmodel.ScoreEventEntry{
+				IDGame:    game.IDGame,
+				IDTeam:    idTeam,
+				Reason:    dbmodel.ScoreReasonTerritory,
+				Points:    cells * pointsPerCell,
+				Objective: fmt.Sprintf("%d cells held", cells),
+				At:        now,
+			}
+			if _, err := a.scores.Record(score); err != nil {
+				log.Printf("Failed to credit territory of team %s: %s\n", idTeam, err.Error())
+				continue
+			}
+			a.events.ScoreChanged(score)
+		}
+	}
+}
@@ -1,14 +1,19 @@
 package user
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 
 	"bombparty.com/bombparty-api/config"
 	"bombparty.com/bombparty-api/database/dbmodel"
 	"bombparty.com/bombparty-api/pkg/authentication"
 	"bombparty.com/bombparty-api/pkg/model"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UserConfig struct {
@@ -135,6 +140,7 @@ func (config *UserConfig) GetOneUser(w http.ResponseWriter, r *http.Request) {
 // @Param email query string true "Email"
 // @Param user body model.UserUpdatePayload true "User update data"
 // @Success 200 {object} map[string]string "token : tokenJwt"
+// @Failure 403 {object} map[string]string "Email reserved to an admin"
 // @Failure 404 {object} map[string]string "User not found"
 // @Failure 500 {object} map[string]string "Server Error"
 // @Router /api/v1/puser/update [put]
@@ -145,6 +151,13 @@ func (config *UserConfig) Update(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, map[string]string{"message": "Error with the payload", "error": err.Error()})
 		return
 	}
+	// Admin emails are promoted when the server starts, taking one over would grant the role
+	if req.Email != email && slices.Contains(config.AdminEmails, req.Email) {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"message": "This email is reserved"})
+		return
+	}
+
 	userEntry := createUserEntryFromUpdate(req)
 	user, err := config.UserRepository.Update(userEntry, email)
 	if err != nil {
@@ -161,6 +174,55 @@ func (config *UserConfig) Update(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, map[string]string{"token": token})
 }
 
+// UpdateSkill godoc
+// @Summary Update the skill of a user
+// @Description Set the skill used by the skill balance strategy, only the admins can
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Id User"
+// @Param skill body model.UserSkillPayload true "Skill between 1 and 3000"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]string "Invalid id or payload"
+// @Failure 403 {object} map[string]string "Admin role required"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /api/v1/users/{id}/skill [put]
+func (config *UserConfig) UpdateSkill(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"message": "Invalid id"})
+		return
+	}
+
+	req := &model.UserSkillPayload{}
+	if err := render.Bind(r, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"message": "Error with the payload", "error": err.Error()})
+		return
+	}
+
+	if err := config.UserRepository.SetSkill(id, req.Skill); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"message": "User not found"})
+			return
+		}
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"message": "Error during the update", "error": err.Error()})
+		return
+	}
+
+	user, err := config.UserRepository.FindOne("id_user", id.String())
+	if err != nil {
+		render.JSON(w, r, map[string]string{"message": "Error during fetching", "error": err.Error()})
+		return
+	}
+
+	render.JSON(w, r, convertToResponse(user))
+}
+
 // DeleteUser godoc
 // @Summary Delete User
 // @Description Delete a utilisateur with their id
@@ -217,6 +279,8 @@ func convertToResponse(user *dbmodel.UserEntry) model.UserResponse {
 		IdUser:   user.IDUser,
 		UserName: user.UserName,
 		Email:    user.Email,
+		Skill:    user.Skill,
+		Role:     string(user.Role),
 	}
 	if user.IDTeam != nil {
 		response.IdTeam = *user.IDTeam
@@ -15,5 +15,10 @@ func Routes(config *config.Config) *chi.Mux {
 	router.Put("/update", UserConfig.Update)
 	router.Delete("/delete", UserConfig.DeleteUser)
 	router.Get("/user", UserConfig.GetOneUser)
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AdminMiddleware(config.UserRepository))
+
+		router.Put("/{id}/skill", UserConfig.UpdateSkill)
+	})
 	return router
 }